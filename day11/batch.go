@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
+)
+
+// BATCH_COMMAND simulates every scenario file in a directory, e.g. "./main.out batch -dir scenarios -rounds 20,10000"
+const BATCH_COMMAND = "batch"
+
+// batchJob is one scenario simulated for one number of rounds
+type batchJob struct {
+	file   string
+	rounds uint
+}
+
+type batchResult struct {
+	report Report
+	err    error
+}
+
+// RunBatch simulates many scenarios across a pool of workers, as directed by args (not including the command), returning the exit code
+// Results are listed in order of file name, then rounds, however the work was shared out
+func RunBatch(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet(BATCH_COMMAND, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	dir := fs.String("dir", "", "directory of scenario files, each in the format of the puzzle input")
+	pattern := fs.String("pattern", "*.txt", "which files in the directory are scenarios; expected-answers files are always skipped")
+	rounds_list := fs.String("rounds", "10000", "comma-separated round counts to simulate each scenario for")
+	relief := fs.String("relief", RELIEF_PRODUCT, "relief policy to simulate with (see ParseReliefPolicy)")
+	big_worry := fs.Bool("big_worry", false, "keep worry levels with no limit on their size, so exact relief policies cannot overflow")
+	fast_forward := fs.Bool("fast_forward", true, "skip ahead once items' rounds start repeating")
+	workers := fs.Int("workers", runtime.NumCPU(), "how many scenarios to simulate at once")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return solver.EXIT_OK
+		}
+		return solver.EXIT_USAGE
+	}
+
+	if len(*dir) == 0 || *workers < 1 {
+		fmt.Fprintf(stderr, "%s: a directory and at least 1 worker are needed\n", BATCH_COMMAND)
+		return solver.EXIT_USAGE
+	}
+
+	rounds := []uint{}
+	for _, r := range strings.Split(*rounds_list, ",") {
+		num, err := strconv.ParseUint(strings.TrimSpace(r), 10, 0)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: rounds: %v\n", BATCH_COMMAND, err)
+			return solver.EXIT_USAGE
+		}
+		rounds = append(rounds, uint(num))
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+
+	relief_policy, err := ParseReliefPolicy(*relief)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", BATCH_COMMAND, err)
+		return solver.EXIT_USAGE
+	}
+
+	// find scenarios; Glob sorts them by name
+	files, err := filepath.Glob(filepath.Join(*dir, *pattern))
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", BATCH_COMMAND, err)
+		return solver.EXIT_USAGE
+	}
+
+	scenarios := make(map[string][]*Monkey)
+	parse_errs := make(map[string]error)
+	jobs := []batchJob{}
+	for _, file := range files {
+		if strings.HasSuffix(strings.TrimSuffix(file, filepath.Ext(file)), solver.ANSWERS_SUFFIX) {
+			continue
+		}
+
+		// each scenario is parsed once, then copied for every simulation of it
+		lines, err := fileutil.GetLinesFromFile(file)
+		if err == nil {
+			scenarios[file], err = ParseMonkeysFromInput(lines)
+		}
+		parse_errs[file] = fileutil.WithFile(err, file)
+
+		for _, r := range rounds {
+			jobs = append(jobs, batchJob{file: file, rounds: r})
+		}
+	}
+
+	if len(jobs) == 0 {
+		fmt.Fprintf(stderr, "%s: no scenarios match %s\n", BATCH_COMMAND, filepath.Join(*dir, *pattern))
+		return solver.EXIT_FAILED
+	}
+
+	// share the jobs out; each result has its own slot, so the order they finish in does not matter
+	results := make([]batchResult, len(jobs))
+	job_indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range job_indexes {
+				job := jobs[i]
+				if parse_errs[job.file] != nil {
+					results[i].err = parse_errs[job.file]
+					continue
+				}
+
+				opts := SimulationOptions{Relief: relief_policy, BigWorry: *big_worry, FastForward: *fast_forward}
+				results[i].report, results[i].err = MonkeyBusiness(CopyMonkeys(scenarios[job.file]), job.rounds, opts)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		job_indexes <- i
+	}
+	close(job_indexes)
+	wg.Wait()
+
+	// table of results, with problems listed after it
+	exit_code := solver.EXIT_OK
+	problems := []string{}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "scenario\trounds\tmonkeys\tmonkey business\tinspections by monkey")
+	for i, job := range jobs {
+		name, err := filepath.Rel(*dir, job.file)
+		if err != nil {
+			name = job.file
+		}
+
+		r := results[i]
+		if r.err != nil {
+			fmt.Fprintf(tw, "%s\t%d\t-\tFAILED\t-\n", name, job.rounds)
+			problems = append(problems, fmt.Sprintf("%s for %d rounds: %v", name, job.rounds, r.err))
+			exit_code = solver.EXIT_FAILED
+			continue
+		}
+
+		inspections := make([]string, len(r.report.Monkeys))
+		for m, stats := range r.report.Monkeys {
+			inspections[m] = fmt.Sprintf("%d:%d", stats.ID, stats.Inspections)
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%v\t%s\n", name, job.rounds, len(r.report.Monkeys), r.report.Business, strings.Join(inspections, " "))
+	}
+	tw.Flush()
+
+	for _, p := range problems {
+		fmt.Fprintf(stderr, "%s: %s\n", BATCH_COMMAND, p)
+	}
+
+	return exit_code
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
+)
+
+// generateScenarios writes count random troops to a new directory, with operations that overflow uint64 within 20 rounds under exact relief
+func generateScenarios(t *testing.T, count int) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	var stderr strings.Builder
+	args := []string{"-out", dir, "-count", fmt.Sprint(count), "-monkeys", "5", "-ops", "add=1,compound=1,square=1"}
+	if code := RunGenerate(args, &strings.Builder{}, &stderr); code != solver.EXIT_OK {
+		t.Fatalf("generate exited with %d: %s", code, stderr.String())
+	}
+
+	return dir
+}
+
+func TestRunBatchOrder(t *testing.T) {
+	const TROOPS = 6
+	dir := generateScenarios(t, TROOPS)
+
+	// rounds are listed out of order, to be sorted
+	want_rows := []string{}
+	for i := 0; i < TROOPS; i++ {
+		for _, rounds := range []int{1, 7, 20} {
+			want_rows = append(want_rows, fmt.Sprintf("troop_%03d.txt %d", i, rounds))
+		}
+	}
+
+	outputs := []string{}
+	for _, workers := range []int{1, 4, 2 * TROOPS} {
+		var stdout, stderr strings.Builder
+		args := []string{"-dir", dir, "-rounds", "20,1,7", "-relief", "divide:3", "-big_worry", "-workers", fmt.Sprint(workers)}
+		if code := RunBatch(args, &stdout, &stderr); code != solver.EXIT_OK {
+			t.Fatalf("%d workers: exit code %d, stderr:\n%s", workers, code, stderr.String())
+		}
+
+		// every row after the header starts with its scenario and rounds
+		rows := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")[1:]
+		got_rows := make([]string, len(rows))
+		for i, row := range rows {
+			fields := strings.Fields(row)
+			got_rows[i] = strings.Join(fields[:2], " ")
+		}
+
+		if strings.Join(got_rows, "\n") != strings.Join(want_rows, "\n") {
+			t.Errorf("%d workers: got rows in order\n%s\nwant\n%s", workers, strings.Join(got_rows, "\n"), strings.Join(want_rows, "\n"))
+		}
+		outputs = append(outputs, stdout.String())
+	}
+
+	// however the work is shared out, the results are the same
+	for i := 1; i < len(outputs); i++ {
+		if outputs[i] != outputs[0] {
+			t.Errorf("results differ with the number of workers:\n%s\nand\n%s", outputs[0], outputs[i])
+		}
+	}
+}
+
+func TestRunBatchBigWorry(t *testing.T) {
+	dir := generateScenarios(t, 3)
+
+	// squaring overflows uint64 worry levels, unless they have no limit
+	for _, big_worry := range []bool{false, true} {
+		var stdout, stderr strings.Builder
+		args := []string{"-dir", dir, "-rounds", "20", "-relief", "divide:3", fmt.Sprintf("-big_worry=%v", big_worry)}
+		code := RunBatch(args, &stdout, &stderr)
+
+		switch {
+		case big_worry && code != solver.EXIT_OK:
+			t.Errorf("big worry: exit code %d, stderr:\n%s", code, stderr.String())
+		case !big_worry && (code != solver.EXIT_FAILED || !strings.Contains(stderr.String(), ErrWorryOverflow.Error())):
+			t.Errorf("uint64 worry: exit code %d, want %d for overflowing, stderr:\n%s", code, solver.EXIT_FAILED, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrCountOverflow is returned when a monkey would inspect more items than fit in uint64
+var ErrCountOverflow = errors.New("inspection count does not fit in uint64")
+
+// itemState is where an item is at the start of a round: which monkey holds it, and its worry level reduced by the divisor product
+// Items never affect each other, so an item's state fully decides its future; and there are finitely many states, so every item eventually repeats one
+type itemState struct {
+	holder int // monkey index
+	worry  uint64
+}
+
+// itemTrajectory records the rounds of one item until its state repeats (or the rounds run out)
+type itemTrajectory struct {
+	states     []itemState // state at the start of each round
+	throws     []throw     // every throw of the item, in order, over all rounds
+	round_ends []int       // index into throws just past each round's throws
+
+	cycle_start  int // first round of the repeating part; only meaningful if cycle_length > 0
+	cycle_length int
+}
+
+// throw is a monkey inspecting an item and throwing it to another monkey, by their indexes
+type throw struct {
+	from, to int
+}
+
+// playRound moves one item through a single round, returning its state at the start of the next round
+// A monkey throwing to a monkey with a higher id has the item inspected again this round; otherwise it waits for the next round
+func playRound(monkeys []*Monkey, s itemState, divisor_product uint64, throws []throw) (itemState, []throw) {
+	for {
+		m := monkeys[s.holder]
+
+		s.worry = m.inspect_expr.EvaluateMod(s.worry, divisor_product)
+		dest_monkey := m.false_dest
+		if s.worry%m.divisible_test == 0 {
+			dest_monkey = m.true_dest
+		}
+		throws = append(throws, throw{from: m.index, to: dest_monkey})
+
+		if dest_monkey <= s.holder {
+			return itemState{holder: dest_monkey, worry: s.worry}, throws
+		}
+		s.holder = dest_monkey
+	}
+}
+
+// traceItem follows an item from its starting state until a state repeats, or for all of the rounds if that comes first
+func traceItem(monkeys []*Monkey, start itemState, rounds uint, divisor_product uint64) itemTrajectory {
+	t := itemTrajectory{}
+	seen := make(map[itemState]int)
+
+	s := start
+	for round := 0; uint(round) < rounds; round++ {
+		if first, found := seen[s]; found {
+			t.cycle_start = first
+			t.cycle_length = round - first
+			break
+		}
+
+		seen[s] = round
+		t.states = append(t.states, s)
+
+		s, t.throws = playRound(monkeys, s, divisor_product, t.throws)
+		t.round_ends = append(t.round_ends, len(t.throws))
+	}
+
+	// the state after the last traced round is needed if there was no cycle
+	t.states = append(t.states, s)
+
+	return t
+}
+
+// countThrows adds the throws during rounds [from, to) of the trajectory to counts (by thrower, then destination), times repeat
+func (t itemTrajectory) countThrows(from, to int, repeat uint64, counts [][]uint64) error {
+	if from == to || repeat == 0 {
+		return nil
+	}
+
+	start := 0
+	if from > 0 {
+		start = t.round_ends[from-1]
+	}
+
+	for _, th := range t.throws[start:t.round_ends[to-1]] {
+		var carry uint64
+		counts[th.from][th.to], carry = bits.Add64(counts[th.from][th.to], repeat, 0)
+		if carry != 0 {
+			return ErrCountOverflow
+		}
+	}
+
+	return nil
+}
+
+// FastForward finds how many items each monkey inspects (and throws to each other monkey) over the given rounds, and where each item ends up, without playing every round
+// Each item's rounds are played only until its state repeats; the inspections in that cycle are then multiplied out to the remaining rounds
+// Worry levels must already be reduced by the divisor product, and every operation must be modular
+// NOTE: items end up with the right monkeys, but not necessarily in the same order within a monkey's queue as playing every round would give
+func FastForward(monkeys []*Monkey, rounds uint, divisor_product uint64) error {
+	counts := make([][]uint64, len(monkeys))
+	for _, m := range monkeys {
+		counts[m.index] = make([]uint64, len(monkeys))
+	}
+
+	// find every item's final state first, as the queues are being walked
+	final_items := []Item{}
+	final_states := []itemState{}
+	for _, m := range monkeys {
+		for i := 0; i < m.reduced_items.Len(); i++ {
+			item := m.reduced_items.At(i)
+			final_items = append(final_items, item)
+			t := traceItem(monkeys, itemState{holder: m.index, worry: item.Worry}, rounds, divisor_product)
+
+			if t.cycle_length == 0 {
+				// the rounds ran out before anything repeated
+				if err := t.countThrows(0, len(t.round_ends), 1, counts); err != nil {
+					return fmt.Errorf("item %d: %w", item.ID, err)
+				}
+				final_states = append(final_states, t.states[len(t.states)-1])
+				continue
+			}
+
+			// rounds before the cycle, then whole cycles, then part of one more
+			remaining := uint64(rounds) - uint64(t.cycle_start)
+			cycles := remaining / uint64(t.cycle_length)
+			leftover := int(remaining % uint64(t.cycle_length))
+
+			if err := t.countThrows(0, t.cycle_start, 1, counts); err != nil {
+				return fmt.Errorf("item %d: %w", item.ID, err)
+			}
+			if err := t.countThrows(t.cycle_start, t.cycle_start+t.cycle_length, cycles, counts); err != nil {
+				return fmt.Errorf("item %d: %w", item.ID, err)
+			}
+			if err := t.countThrows(t.cycle_start, t.cycle_start+leftover, 1, counts); err != nil {
+				return fmt.Errorf("item %d: %w", item.ID, err)
+			}
+
+			final_states = append(final_states, t.states[t.cycle_start+leftover])
+		}
+	}
+
+	// every throw was an inspection
+	for _, m := range monkeys {
+		for dest, count := range counts[m.index] {
+			var carry uint64
+			m.throws_to[dest], carry = bits.Add64(m.throws_to[dest], count, 0)
+			if carry != 0 {
+				return fmt.Errorf("monkey %d: %w", m.id, ErrCountOverflow)
+			}
+
+			m.inspections_performed, carry = bits.Add64(m.inspections_performed, count, 0)
+			if carry != 0 {
+				return fmt.Errorf("monkey %d: %w", m.id, ErrCountOverflow)
+			}
+		}
+	}
+
+	// hand every item to its final monkey
+	for _, m := range monkeys {
+		m.reduced_items.Clear()
+	}
+	for i, s := range final_states {
+		final_items[i].Worry = s.worry
+		monkeys[s.holder].reduced_items.PushBack(final_items[i])
+	}
+
+	return nil
+}
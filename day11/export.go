@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Formats for exported traces and reports
+const (
+	EXPORT_CSV  = "csv"  // a header row, then one row per record
+	EXPORT_JSON = "json" // one object per line
+)
+
+func checkExportFormat(format string) error {
+	switch format {
+	case EXPORT_CSV, EXPORT_JSON:
+		return nil
+	}
+
+	return fmt.Errorf("unknown export format '%s' (expected %s or %s)", format, EXPORT_CSV, EXPORT_JSON)
+}
+
+// createPartFile creates the file to export one part's records to; each part gets its own, e.g. trace_part1.csv for trace.csv
+func createPartFile(name string, part int) (*os.File, error) {
+	ext := filepath.Ext(name)
+	return os.Create(fmt.Sprintf("%s_part%d%s", strings.TrimSuffix(name, ext), part, ext))
+}
+
+// closeFile closes f, storing any error in err unless it already holds one; it must be deferred
+func closeFile(f *os.File, err *error) {
+	if close_err := f.Close(); *err == nil {
+		*err = close_err
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"unicode"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+)
+
+// Errors from evaluating expressions on worry levels
+var (
+	ErrWorryOverflow  = errors.New("worry level does not fit in uint64 (the big worry mode has no limit)")
+	ErrDivisionByZero = errors.New("division by zero")
+)
+
+// Expression is a node of the arithmetic expression a monkey uses to change an item's worry level, e.g. "old * (old + 2)"
+type Expression interface {
+	// Evaluate computes the exact result, failing with ErrWorryOverflow if it (or any step) is not within the range of uint64
+	Evaluate(old uint64) (uint64, error)
+	// EvaluateMod computes the result modulo modulus, given old < modulus; it is only valid if IsModular
+	EvaluateMod(old, modulus uint64) uint64
+	// EvaluateBig computes the exact result with no limit on its size
+	EvaluateBig(old *big.Int) (*big.Int, error)
+	// IsModular is true if the expression only uses operations that give the same remainder when applied to remainders (+, -, *)
+	IsModular() bool
+	String() string
+}
+
+// OldValue is the worry level before the operation
+type OldValue struct{}
+
+func (OldValue) Evaluate(old uint64) (uint64, error)        { return old, nil }
+func (OldValue) EvaluateMod(old, _ uint64) uint64           { return old }
+func (OldValue) EvaluateBig(old *big.Int) (*big.Int, error) { return old, nil }
+func (OldValue) IsModular() bool                            { return true }
+func (OldValue) String() string                             { return "old" }
+
+// LiteralValue is a constant number
+type LiteralValue struct {
+	val uint64
+}
+
+func (l LiteralValue) Evaluate(_ uint64) (uint64, error)    { return l.val, nil }
+func (l LiteralValue) EvaluateMod(_, modulus uint64) uint64 { return l.val % modulus }
+func (l LiteralValue) EvaluateBig(_ *big.Int) (*big.Int, error) {
+	return new(big.Int).SetUint64(l.val), nil
+}
+func (l LiteralValue) IsModular() bool { return true }
+func (l LiteralValue) String() string  { return strconv.FormatUint(l.val, 10) }
+
+// BinaryOperation applies one of OPERATORS to the results of two other expressions
+type BinaryOperation struct {
+	operator    byte
+	left, right Expression
+}
+
+func (b BinaryOperation) Evaluate(old uint64) (uint64, error) {
+	l, err := b.left.Evaluate(old)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := b.right.Evaluate(old)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.operator {
+	case '+':
+		sum, carry := bits.Add64(l, r, 0)
+		if carry != 0 {
+			return 0, fmt.Errorf("%d + %d: %w", l, r, ErrWorryOverflow)
+		}
+		return sum, nil
+	case '-':
+		if r > l {
+			return 0, fmt.Errorf("%d - %d: %w", l, r, ErrWorryOverflow)
+		}
+		return l - r, nil
+	case '*':
+		hi, lo := bits.Mul64(l, r)
+		if hi != 0 {
+			return 0, fmt.Errorf("%d * %d: %w", l, r, ErrWorryOverflow)
+		}
+		return lo, nil
+	}
+
+	if r == 0 {
+		return 0, ErrDivisionByZero
+	}
+	if b.operator == '/' {
+		return l / r, nil
+	}
+	return l % r, nil
+}
+
+func (b BinaryOperation) EvaluateMod(old, modulus uint64) uint64 {
+	l := b.left.EvaluateMod(old, modulus)
+	r := b.right.EvaluateMod(old, modulus)
+
+	// l and r are both less than modulus, so none of these can overflow
+	switch b.operator {
+	case '+':
+		sum, carry := bits.Add64(l, r, 0)
+		if carry != 0 || sum >= modulus {
+			sum -= modulus
+		}
+		return sum
+	case '-':
+		if l >= r {
+			return l - r
+		}
+		return modulus - (r - l)
+	default: // '*'; IsModular rules out the rest
+		hi, lo := bits.Mul64(l, r)
+		_, rem := bits.Div64(hi, lo, modulus)
+		return rem
+	}
+}
+
+func (b BinaryOperation) EvaluateBig(old *big.Int) (*big.Int, error) {
+	l, err := b.left.EvaluateBig(old)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := b.right.EvaluateBig(old)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(big.Int)
+	switch b.operator {
+	case '+':
+		return result.Add(l, r), nil
+	case '-':
+		return result.Sub(l, r), nil
+	case '*':
+		return result.Mul(l, r), nil
+	}
+
+	if r.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	// truncated division, matching Go's integer operators
+	if b.operator == '/' {
+		return result.Quo(l, r), nil
+	}
+	return result.Rem(l, r), nil
+}
+
+func (b BinaryOperation) IsModular() bool {
+	switch b.operator {
+	case '+', '-', '*':
+		return b.left.IsModular() && b.right.IsModular()
+	}
+
+	return false
+}
+
+func (b BinaryOperation) String() string {
+	return fmt.Sprintf("(%v %c %v)", b.left, b.operator, b.right)
+}
+
+// Operator precedence: higher binds tighter
+var OPERATORS = map[byte]int{
+	'+': 1,
+	'-': 1,
+	'*': 2,
+	'/': 2,
+	'%': 2,
+}
+
+// token is a single piece of an expression, with its 1-indexed column in the expression text
+type token struct {
+	text   string
+	column int
+}
+
+func tokenizeExpression(text string) ([]token, error) {
+	tokens := []token{}
+
+	for i := 0; i < len(text); {
+		c := rune(text[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c):
+			start := i
+			for ; i < len(text) && unicode.IsDigit(rune(text[i])); i++ {
+			}
+			tokens = append(tokens, token{text: text[start:i], column: start + 1})
+		case unicode.IsLetter(c):
+			start := i
+			for ; i < len(text) && unicode.IsLetter(rune(text[i])); i++ {
+			}
+			if text[start:i] != "old" {
+				return nil, fileutil.Errorf(0, start+1, "unsupported name '%s' (only 'old' is known)", text[start:i])
+			}
+			tokens = append(tokens, token{text: text[start:i], column: start + 1})
+		default:
+			if _, is_operator := OPERATORS[text[i]]; !is_operator && c != '(' && c != ')' {
+				return nil, fileutil.Errorf(0, i+1, "unsupported character '%c'", c)
+			}
+			tokens = append(tokens, token{text: text[i : i+1], column: i + 1})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// expressionParser is a recursive descent parser over the tokens of one expression
+type expressionParser struct {
+	tokens []token
+	pos    int
+	end    int // column just past the end of the text, for errors about missing tokens
+}
+
+func (p *expressionParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{column: p.end}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+// parseBinary parses operations at or above the given precedence, which are left-associative
+func (p *expressionParser) parseBinary(min_precedence int) (Expression, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t, found := p.peek()
+		if !found || len(t.text) != 1 {
+			return left, nil
+		}
+
+		precedence, is_operator := OPERATORS[t.text[0]]
+		if !is_operator || precedence < min_precedence {
+			return left, nil
+		}
+		p.pos++
+
+		right, err := p.parseBinary(precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		// dividing by a literal 0 can be caught now rather than when monkeys start throwing
+		if lit, is_literal := right.(LiteralValue); is_literal && lit.val == 0 && (t.text[0] == '/' || t.text[0] == '%') {
+			return nil, fileutil.Errorf(0, t.column, "%w", ErrDivisionByZero)
+		}
+
+		left = BinaryOperation{operator: t.text[0], left: left, right: right}
+	}
+}
+
+// parseOperand parses "old", a literal, or a parenthesized expression
+func (p *expressionParser) parseOperand() (Expression, error) {
+	t, found := p.peek()
+	if !found {
+		return nil, fileutil.Errorf(0, t.column, "expression ends where a value was expected")
+	}
+	p.pos++
+
+	switch {
+	case t.text == "old":
+		return OldValue{}, nil
+
+	case unicode.IsDigit(rune(t.text[0])):
+		val, err := strconv.ParseUint(t.text, 10, 64)
+		if err != nil {
+			return nil, fileutil.Errorf(0, t.column, "%w", err)
+		}
+		return LiteralValue{val: val}, nil
+
+	case t.text == "(":
+		inner, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+
+		closing, found := p.peek()
+		if !found || closing.text != ")" {
+			return nil, fileutil.Errorf(0, t.column, "'(' is never closed")
+		}
+		p.pos++
+
+		return inner, nil
+	}
+
+	return nil, fileutil.Errorf(0, t.column, "unexpected '%s' where a value was expected", t.text)
+}
+
+// ParseExpression parses an expression of +, -, *, /, %, parentheses, "old" and non-negative integer literals
+// Errors are a *fileutil.PositionError with the column within text
+func ParseExpression(text string) (Expression, error) {
+	tokens, err := tokenizeExpression(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &expressionParser{tokens: tokens, end: len(text) + 1}
+	expr, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if extra, found := p.peek(); found {
+		return nil, fileutil.Errorf(0, extra.column, "unexpected '%s' after the end of the expression", extra.text)
+	}
+
+	return expr, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // fully parenthesized
+		at10 uint64 // the result when old is 10
+	}{
+		{"old * 19", "(old * 19)", 190},
+		{"7", "7", 7},
+		{"old + 2 * 3", "(old + (2 * 3))", 16},
+		{"old * 2 + 3", "((old * 2) + 3)", 23},
+		{"(old + 2) * 3", "((old + 2) * 3)", 36},
+		{"old - 2 - 1", "((old - 2) - 1)", 7},
+		{"old / 2 % 3", "((old / 2) % 3)", 2},
+		{"old % (3 + 1) * old", "((old % (3 + 1)) * old)", 20},
+		{"((old))", "old", 10},
+		{"old*old", "(old * old)", 100},
+	}
+
+	for _, test := range tests {
+		expr, err := ParseExpression(test.text)
+		if err != nil {
+			t.Errorf("'%s': unexpected error %v", test.text, err)
+			continue
+		}
+
+		if got := expr.String(); got != test.want {
+			t.Errorf("'%s': parsed as %s, want %s", test.text, got, test.want)
+		}
+		if got, err := expr.Evaluate(10); err != nil || got != test.at10 {
+			t.Errorf("'%s': got %d (error %v) for old = 10, want %d", test.text, got, err, test.at10)
+		}
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		text   string
+		column int
+		want   string
+	}{
+		{"", 1, "expression ends where a value was expected"},
+		{"old +", 6, "expression ends where a value was expected"},
+		{"* 2", 1, "unexpected '*' where a value was expected"},
+		{"(old + 2", 1, "'(' is never closed"},
+		{"old * ((old + 2)", 7, "'(' is never closed"},
+		{"old + 2)", 8, "unexpected ')' after the end of the expression"},
+		{"old old", 5, "unexpected 'old' after the end of the expression"},
+		{"old ^ 2", 5, "unsupported character '^'"},
+		{"old * new", 7, "unsupported name 'new' (only 'old' is known)"},
+		{"old / 0", 5, ErrDivisionByZero.Error()},
+		{"old + 1 % (0)", 9, ErrDivisionByZero.Error()},
+		{"old + 99999999999999999999", 7, `strconv.ParseUint: parsing "99999999999999999999": value out of range`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseExpression(test.text)
+
+		var pe *fileutil.PositionError
+		if !errors.As(err, &pe) {
+			t.Errorf("'%s': got error %v, want a *fileutil.PositionError", test.text, err)
+			continue
+		}
+
+		if pe.Column != test.column || pe.Err.Error() != test.want {
+			t.Errorf("'%s': got error '%v' at column %d, want '%s' at column %d", test.text, pe.Err, pe.Column, test.want, test.column)
+		}
+	}
+}
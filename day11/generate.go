@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
+)
+
+// GENERATE_COMMAND writes random monkey troops in the format of the puzzle input, e.g. "./main.out generate -monkeys 20 -seed 7"
+const GENERATE_COMMAND = "generate"
+
+// Kinds of operation a generated monkey can have
+const (
+	OP_ADD      = "add"      // old + N
+	OP_MULTIPLY = "multiply" // old * N
+	OP_SQUARE   = "square"   // old * old
+	OP_COMPOUND = "compound" // old * N + M
+)
+
+// GeneratorOptions describe the random troops to generate
+type GeneratorOptions struct {
+	Monkeys  int
+	MinItems int // per monkey
+	MaxItems int
+	MaxWorry uint64 // of starting items
+	// OpWeights are how likely each kind of operation is, relative to the others
+	OpWeights  map[string]int
+	MaxOperand uint64
+	// Primes is how many of the smallest primes divisors are chosen from; they are all different if there are at least as many as monkeys
+	Primes int
+	Seed   int64
+}
+
+// DefaultGeneratorOptions are like the puzzle input
+func DefaultGeneratorOptions() GeneratorOptions {
+	return GeneratorOptions{
+		Monkeys:    8,
+		MinItems:   1,
+		MaxItems:   8,
+		MaxWorry:   99,
+		OpWeights:  map[string]int{OP_ADD: 4, OP_MULTIPLY: 2, OP_SQUARE: 1},
+		MaxOperand: 19,
+		Primes:     9,
+		Seed:       1,
+	}
+}
+
+func (opts GeneratorOptions) validate() error {
+	switch {
+	case opts.Monkeys < 2:
+		return fmt.Errorf("at least 2 monkeys are needed, so they have someone to throw to")
+	case opts.MinItems < 0 || opts.MaxItems < opts.MinItems:
+		return fmt.Errorf("item counts must be from 0 up, with the minimum at most the maximum")
+	case opts.MaxOperand < 1 || opts.MaxWorry < 1:
+		return fmt.Errorf("maximum operand and worry level must be at least 1")
+	case opts.MaxOperand > math.MaxInt64 || opts.MaxWorry > math.MaxInt64:
+		// they are drawn with rand.Int63n
+		return fmt.Errorf("maximum operand and worry level must be at most %d", int64(math.MaxInt64))
+	case opts.Primes < 1:
+		return fmt.Errorf("divisors must be chosen from at least 1 prime")
+	}
+
+	total := 0
+	for op, weight := range opts.OpWeights {
+		switch op {
+		case OP_ADD, OP_MULTIPLY, OP_SQUARE, OP_COMPOUND:
+		default:
+			return fmt.Errorf("unknown operation kind '%s'", op)
+		}
+		if weight < 0 {
+			return fmt.Errorf("operation kind '%s' has a negative weight", op)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return fmt.Errorf("at least one operation kind needs a positive weight")
+	}
+
+	return nil
+}
+
+// smallestPrimes returns the first n primes
+func smallestPrimes(n int) []uint64 {
+	primes := []uint64{}
+	for candidate := uint64(2); len(primes) < n; candidate++ {
+		is_prime := true
+		for _, p := range primes {
+			if p*p > candidate {
+				break
+			}
+			if candidate%p == 0 {
+				is_prime = false
+				break
+			}
+		}
+
+		if is_prime {
+			primes = append(primes, candidate)
+		}
+	}
+
+	return primes
+}
+
+// GenerateTroop writes a random troop of monkeys in the format ParseMonkeysFromInput reads; the same options always give the same troop
+func GenerateTroop(opts GeneratorOptions, w io.Writer) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	r := rand.New(rand.NewSource(opts.Seed))
+
+	// divisors: different primes if there are enough, otherwise any of them
+	primes := smallestPrimes(opts.Primes)
+	r.Shuffle(len(primes), func(i, j int) { primes[i], primes[j] = primes[j], primes[i] })
+	divisors := make([]uint64, opts.Monkeys)
+	for i := range divisors {
+		if len(primes) >= opts.Monkeys {
+			divisors[i] = primes[i]
+		} else {
+			divisors[i] = primes[r.Intn(len(primes))]
+		}
+	}
+
+	// operation kinds in a fixed order, so map iteration does not change the troop
+	ops := []string{}
+	total_weight := 0
+	for op, weight := range opts.OpWeights {
+		ops = append(ops, op)
+		total_weight += weight
+	}
+	sort.Strings(ops)
+
+	operand := func() uint64 { return 1 + uint64(r.Int63n(int64(opts.MaxOperand))) }
+
+	bw := bufio.NewWriter(w)
+	for id := 0; id < opts.Monkeys; id++ {
+		if id > 0 {
+			fmt.Fprintln(bw)
+		}
+
+		fmt.Fprintf(bw, "Monkey %d:\n", id)
+
+		items := make([]string, opts.MinItems+r.Intn(opts.MaxItems-opts.MinItems+1))
+		for i := range items {
+			items[i] = strconv.FormatUint(1+uint64(r.Int63n(int64(opts.MaxWorry))), 10)
+		}
+		fmt.Fprintf(bw, "  Starting items: %s\n", strings.Join(items, ", "))
+
+		// pick an operation kind by weight
+		pick := r.Intn(total_weight)
+		op := ops[0]
+		for _, o := range ops {
+			if pick < opts.OpWeights[o] {
+				op = o
+				break
+			}
+			pick -= opts.OpWeights[o]
+		}
+
+		switch op {
+		case OP_ADD:
+			fmt.Fprintf(bw, "  Operation: new = old + %d\n", operand())
+		case OP_MULTIPLY:
+			fmt.Fprintf(bw, "  Operation: new = old * %d\n", operand())
+		case OP_SQUARE:
+			fmt.Fprintf(bw, "  Operation: new = old * old\n")
+		case OP_COMPOUND:
+			fmt.Fprintf(bw, "  Operation: new = old * %d + %d\n", operand(), operand())
+		}
+
+		fmt.Fprintf(bw, "  Test: divisible by %d\n", divisors[id])
+
+		// throw to two different other monkeys, if there are two
+		true_dest := (id + 1 + r.Intn(opts.Monkeys-1)) % opts.Monkeys
+		false_dest := true_dest
+		if opts.Monkeys > 2 {
+			for false_dest == true_dest {
+				false_dest = (id + 1 + r.Intn(opts.Monkeys-1)) % opts.Monkeys
+			}
+		}
+		fmt.Fprintf(bw, "    If true: throw to monkey %d\n", true_dest)
+		fmt.Fprintf(bw, "    If false: throw to monkey %d\n", false_dest)
+	}
+
+	return bw.Flush()
+}
+
+// parseOpWeights reads weights like "add=4,multiply=2,square=1"
+func parseOpWeights(text string) (map[string]int, error) {
+	weights := make(map[string]int)
+	for _, pair := range strings.Split(text, ",") {
+		op, weight_str, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			return nil, fmt.Errorf("expected kind=weight, got '%s'", pair)
+		}
+
+		weight, err := strconv.Atoi(weight_str)
+		if err != nil {
+			return nil, fmt.Errorf("weight of '%s': %w", op, err)
+		}
+		weights[op] = weight
+	}
+
+	return weights, nil
+}
+
+// RunGenerate writes random troops as directed by args (not including the command), returning the exit code
+// One troop is written to stdout, unless a directory is given for several
+func RunGenerate(args []string, stdout, stderr io.Writer) int {
+	defaults := DefaultGeneratorOptions()
+	opts := defaults
+
+	fs := flag.NewFlagSet(GENERATE_COMMAND, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	fs.IntVar(&opts.Monkeys, "monkeys", defaults.Monkeys, "how many monkeys are in each troop")
+	fs.IntVar(&opts.MinItems, "min_items", defaults.MinItems, "fewest starting items a monkey has")
+	fs.IntVar(&opts.MaxItems, "max_items", defaults.MaxItems, "most starting items a monkey has")
+	fs.Uint64Var(&opts.MaxWorry, "max_worry", defaults.MaxWorry, "highest worry level of a starting item")
+	ops := fs.String("ops", "add=4,multiply=2,square=1", "relative weights of operation kinds: "+strings.Join([]string{OP_ADD, OP_MULTIPLY, OP_SQUARE, OP_COMPOUND}, ", "))
+	fs.Uint64Var(&opts.MaxOperand, "max_operand", defaults.MaxOperand, "highest number in an operation")
+	fs.IntVar(&opts.Primes, "primes", defaults.Primes, "how many of the smallest primes divisors are chosen from")
+	fs.Int64Var(&opts.Seed, "seed", defaults.Seed, "random seed; troop i of a directory uses seed + i")
+	out_dir := fs.String("out", "", "directory to write troops to, as troop_000.txt and so on, instead of stdout")
+	count := fs.Int("count", 1, "how many troops to write to the directory")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return solver.EXIT_OK
+		}
+		return solver.EXIT_USAGE
+	}
+
+	var err error
+	opts.OpWeights, err = parseOpWeights(*ops)
+	if err == nil {
+		err = opts.validate()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", GENERATE_COMMAND, err)
+		return solver.EXIT_USAGE
+	}
+
+	if len(*out_dir) == 0 {
+		if err := GenerateTroop(opts, stdout); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", GENERATE_COMMAND, err)
+			return solver.EXIT_FAILED
+		}
+		return solver.EXIT_OK
+	}
+
+	if err := os.MkdirAll(*out_dir, 0755); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", GENERATE_COMMAND, err)
+		return solver.EXIT_FAILED
+	}
+
+	seed := opts.Seed
+	for i := 0; i < *count; i++ {
+		opts.Seed = seed + int64(i)
+		if err := writeTroopFile(filepath.Join(*out_dir, fmt.Sprintf("troop_%03d.txt", i)), opts); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", GENERATE_COMMAND, err)
+			return solver.EXIT_FAILED
+		}
+	}
+
+	return solver.EXIT_OK
+}
+
+func writeTroopFile(name string, opts GeneratorOptions) (err error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer closeFile(f, &err)
+
+	return GenerateTroop(opts, f)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// GENERATOR_OP_MIXES are operation weights which between them use every kind of operation
+var GENERATOR_OP_MIXES = []map[string]int{
+	{OP_ADD: 4, OP_MULTIPLY: 2, OP_SQUARE: 1},
+	{OP_ADD: 1},
+	{OP_SQUARE: 1},
+	{OP_ADD: 1, OP_COMPOUND: 1, OP_SQUARE: 1},
+	{OP_MULTIPLY: 3, OP_COMPOUND: 1},
+}
+
+// generateTroop returns the troop GenerateTroop writes for opts, failing the test if it cannot
+func generateTroop(tb testing.TB, opts GeneratorOptions) string {
+	tb.Helper()
+
+	var sb strings.Builder
+	if err := GenerateTroop(opts, &sb); err != nil {
+		tb.Fatal(err)
+	}
+
+	return sb.String()
+}
+
+func TestGenerateTroopRoundTrip(t *testing.T) {
+	for _, ops := range GENERATOR_OP_MIXES {
+		for seed := int64(1); seed <= 20; seed++ {
+			opts := DefaultGeneratorOptions()
+			opts.OpWeights = ops
+			opts.Seed = seed
+			opts.Monkeys = 2 + int(seed)%10
+			opts.MinItems = 0
+
+			text := generateTroop(t, opts)
+
+			monkeys, err := ParseMonkeysFromInput(strings.Split(text, "\n"))
+			if err != nil {
+				t.Fatalf("ops %v, seed %d: generated troop does not parse: %v\n%s", ops, seed, err, text)
+			}
+			if len(monkeys) != opts.Monkeys {
+				t.Errorf("ops %v, seed %d: got %d monkeys, want %d", ops, seed, len(monkeys), opts.Monkeys)
+			}
+
+			if again := generateTroop(t, opts); again != text {
+				t.Errorf("ops %v, seed %d: the same options gave a different troop:\n%s\nthen\n%s", ops, seed, text, again)
+			}
+		}
+	}
+
+	// other seeds give other troops
+	opts := DefaultGeneratorOptions()
+	first := generateTroop(t, opts)
+	opts.Seed++
+	if generateTroop(t, opts) == first {
+		t.Errorf("seeds %d and %d gave the same troop", opts.Seed-1, opts.Seed)
+	}
+}
+
+func TestGeneratorOptionsValidate(t *testing.T) {
+	invalid := map[string]func(opts *GeneratorOptions){
+		"one monkey":     func(opts *GeneratorOptions) { opts.Monkeys = 1 },
+		"negative items": func(opts *GeneratorOptions) { opts.MinItems = -1 },
+		"items out of order": func(opts *GeneratorOptions) {
+			opts.MinItems = 3
+			opts.MaxItems = 2
+		},
+		"no operand":        func(opts *GeneratorOptions) { opts.MaxOperand = 0 },
+		"huge worry":        func(opts *GeneratorOptions) { opts.MaxWorry = 1 << 63 },
+		"no primes":         func(opts *GeneratorOptions) { opts.Primes = 0 },
+		"unknown operation": func(opts *GeneratorOptions) { opts.OpWeights = map[string]int{"divide": 1} },
+		"negative weight":   func(opts *GeneratorOptions) { opts.OpWeights = map[string]int{OP_ADD: 2, OP_SQUARE: -1} },
+		"no weight":         func(opts *GeneratorOptions) { opts.OpWeights = map[string]int{OP_ADD: 0} },
+	}
+
+	for name, change := range invalid {
+		opts := DefaultGeneratorOptions()
+		change(&opts)
+
+		if err := GenerateTroop(opts, &strings.Builder{}); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
+
+// FuzzParseMonkeysFromInput checks that no input makes parsing, or simulating what parses, panic
+// Run it with "go test -fuzz=FuzzParseMonkeysFromInput"; generated troops are its starting corpus
+func FuzzParseMonkeysFromInput(f *testing.F) {
+	for i, ops := range GENERATOR_OP_MIXES {
+		opts := DefaultGeneratorOptions()
+		opts.OpWeights = ops
+		opts.Seed = int64(i)
+		opts.Monkeys = 2 + i
+		f.Add(generateTroop(f, opts))
+	}
+	f.Add(strings.Join(troop(), "\n"))
+
+	f.Fuzz(func(t *testing.T, text string) {
+		monkeys, err := ParseMonkeysFromInput(strings.Split(text, "\n"))
+		if err != nil {
+			return
+		}
+
+		// errors are fine, e.g. worry levels overflowing; panics are not
+		for _, relief := range []ReliefPolicy{ProductRelief{}, DivideRelief{N: 3}} {
+			MonkeyBusiness(CopyMonkeys(monkeys), 20, SimulationOptions{Relief: relief, FastForward: true})
+		}
+	})
+}
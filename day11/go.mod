@@ -1,7 +1,12 @@
-module main
+module day11
 
 go 1.19
 
-require github.com/lauragalbraith/AdventOfCode2022/util/gofileutil v0.0.0-00010101000000-000000000000
+require (
+	github.com/lauragalbraith/AdventOfCode2022/util/gofileutil v0.0.0-00010101000000-000000000000
+	github.com/lauragalbraith/AdventOfCode2022/util/gosolver v0.0.0-00010101000000-000000000000
+)
 
 replace github.com/lauragalbraith/AdventOfCode2022/util/gofileutil => ../util/gofileutil
+
+replace github.com/lauragalbraith/AdventOfCode2022/util/gosolver => ../util/gosolver
@@ -2,32 +2,61 @@
 Package main solves Day 11 of Advent of Code 2022
 main.go: Laura Galbraith
 What is the level of monkey business after 20 rounds of stuff-slinging simian shenanigans?
-Compile and run: rm main.out; go clean; FMT_NEEDED=$(gofmt -e -d main.go | wc -l); if [ $FMT_NEEDED = 0 ]; then go build -o main.out main && ./main.out; else gofmt -e -d main.go; fi
+Compile and run: rm main.out; go clean; FMT_NEEDED=$(gofmt -e -d main.go | wc -l); if [ $FMT_NEEDED = 0 ]; then go build -o main.out day11 && ./main.out; else gofmt -e -d main.go; fi
 Go 1.19 used
 */
 package main
 
 import (
-	"container/list" // https://pkg.go.dev/container/list
+	"errors"
 	"fmt"
+	"math/big"
+	"os"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
 
 	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil" // GetLinesFromFile
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
 )
 
-type Operation func(old uint64) (new uint64)
+// MONKEY_BLOCK_LINES is how many lines describe one monkey: id, items, operation, test, true case, false case
+const MONKEY_BLOCK_LINES = 6
+
+type Operation func(old uint64) (new uint64, err error)
+
+// Item is something a monkey holds, with an ID (in order of the input) so it can be followed as it is thrown around
+type Item struct {
+	ID       int
+	Worry    uint64
+	BigWorry *big.Int // used instead of Worry in the big worry mode
+}
+
+// WorryLevel is whichever of Worry or BigWorry is in use
+func (item Item) WorryLevel() any {
+	if item.BigWorry != nil {
+		return item.BigWorry
+	}
+
+	return item.Worry
+}
+
+func (item Item) String() string {
+	return fmt.Sprintf("#%d:%v", item.ID, item.WorryLevel())
+}
 
 type Monkey struct {
-	id             int
-	reduced_items  *list.List
+	id             int // as given in the input
+	index          int // position in the order monkeys take turns in, used to refer to them within a slice
+	reduced_items  *ItemQueue
+	inspect_expr   Expression
 	inspect_op     Operation
 	divisible_test uint64
-	true_dest      int
+	true_dest      int // index of monkey to throw to
 	false_dest     int
 
 	inspections_performed uint64
+	throws_to             []uint64 // by destination monkey index
 }
 
 func (m *Monkey) String() string {
@@ -35,8 +64,8 @@ func (m *Monkey) String() string {
 
 	fmt.Fprintf(&sb, "%d: [", m.id)
 
-	for e := m.reduced_items.Front(); e != nil; e = e.Next() {
-		fmt.Fprintf(&sb, "%+v, ", e.Value)
+	for i := 0; i < m.reduced_items.Len(); i++ {
+		fmt.Fprintf(&sb, "%+v, ", m.reduced_items.At(i))
 	}
 	sb.WriteString("]")
 
@@ -51,230 +80,596 @@ func (m *Monkey) DeepCopy() *Monkey {
 	new_m := new(Monkey)
 
 	new_m.id = m.id
-	new_m.reduced_items = list.New()
-	for e := m.reduced_items.Front(); e != nil; e = e.Next() {
-		original_e := e.Value.(uint64)
+	new_m.index = m.index
+	new_m.reduced_items = NewItemQueue(m.reduced_items.Len())
+	for i := 0; i < m.reduced_items.Len(); i++ {
+		original_e := m.reduced_items.At(i)
+		if original_e.BigWorry != nil {
+			original_e.BigWorry = new(big.Int).Set(original_e.BigWorry)
+		}
 		new_m.reduced_items.PushBack(original_e)
 	}
+	new_m.inspect_expr = m.inspect_expr
 	new_m.inspect_op = m.inspect_op
 	new_m.divisible_test = m.divisible_test
 	new_m.true_dest = m.true_dest
 	new_m.false_dest = m.false_dest
 	new_m.inspections_performed = m.inspections_performed
+	new_m.throws_to = append([]uint64(nil), m.throws_to...)
 
 	return new_m
 }
 
-func ParseMonkeysFromInput(lines []string) ([]*Monkey, error) {
-	// Initialize monkeys
-	monkeys := make([]*Monkey, (len(lines)+1)/7)
+// Records decoded from the lines describing a monkey
+type monkeyIDLine struct {
+	ID int `re:"id"`
+}
 
-	operation_line_re := regexp.MustCompile(`^  Operation: new = old (.) (.+)$`)
-	test_line_re := regexp.MustCompile(`^  Test: divisible by (\d+)$`)
-	action_line_re := regexp.MustCompile(`^    If (true|false): throw to monkey (\d)$`)
+type monkeyItemsLine struct {
+	Items []uint64 `re:"items"`
+}
 
-	for i := 0; i < len(lines); i++ {
-		// Create monkey from block of lines
-		m := new(Monkey)
-		m.inspections_performed = 0
+type monkeyTestLine struct {
+	Divisor uint64 `re:"divisor"`
+}
 
-		// get id
-		var err error
-		m.id, err = strconv.Atoi(lines[i][len(lines[i])-2 : len(lines[i])-1])
-		if err != nil {
-			fmt.Printf("Could not convert '%v' to int\n", lines[i][len(lines[i])-2:len(lines[i])-1])
-			return nil, err
-		}
+type monkeyActionLine struct {
+	Dest int `re:"dest"`
+}
 
-		i++
+var (
+	// Decoders for the lines describing a monkey, in order; the operation is parsed as an Expression
+	id_decoder        = fileutil.MustNewDecoder[monkeyIDLine](`^Monkey (?P<id>\d+):$`)
+	items_decoder     = fileutil.MustNewDecoder[monkeyItemsLine](`^  Starting items: (?P<items>.*)$`)
+	operation_line_re = regexp.MustCompile(`^  Operation: new = (.+)$`)
+	test_decoder      = fileutil.MustNewDecoder[monkeyTestLine](`^  Test: divisible by (?P<divisor>\d+)$`)
+	true_decoder      = fileutil.MustNewDecoder[monkeyActionLine](`^    If true: throw to monkey (?P<dest>\d+)$`)
+	false_decoder     = fileutil.MustNewDecoder[monkeyActionLine](`^    If false: throw to monkey (?P<dest>\d+)$`)
+)
 
-		// get starting items
-		items_str := lines[i][18:]
-		items := strings.Split(items_str, ", ")
-		m.reduced_items = list.New()
-		for _, item := range items {
-			num, err := strconv.ParseInt(item, 10, 64)
-			if err != nil {
-				fmt.Printf("Could not get item number from '%s': %+v\n", item, err)
-				return nil, err
-			}
+// parsedMonkey is a monkey whose lines have been read, with what is needed to report problems found once every monkey is known
+type parsedMonkey struct {
+	m          *Monkey
+	items      []uint64
+	id_line    int
+	true_line  int
+	false_line int
+	true_id    int
+	false_id   int
+
+	// which lines could be read; a monkey with problems elsewhere is still known by its id, so others throwing to it are not also reported
+	id_ok, true_ok, false_ok bool
+}
+
+// lastValueColumn is the column of the value at the end of a line, after its last space
+func lastValueColumn(line string) int {
+	return strings.LastIndexByte(line, ' ') + 2
+}
 
-			m.reduced_items.PushBack(uint64(num))
+// parseMonkey reads the lines describing one monkey, adding every problem found to errs
+func parseMonkey(block fileutil.Block, errs *fileutil.ErrorList) parsedMonkey {
+	lines := block.Lines
+	p := parsedMonkey{m: new(Monkey)}
+
+	// get id
+	id, err := id_decoder.Decode(lines[0])
+	errs.Add(fileutil.AtLine(err, block.FirstLine))
+	p.m.id = id.ID
+	p.id_line = block.FirstLine
+	p.id_ok = err == nil
+
+	// get starting items
+	items, err := items_decoder.Decode(lines[1])
+	errs.Add(fileutil.AtLine(err, block.FirstLine+1))
+	p.items = items.Items
+
+	// get operation
+	op_details := operation_line_re.FindStringSubmatchIndex(lines[2])
+	if op_details == nil {
+		errs.Add(fileutil.Errorf(block.FirstLine+2, 0, "'%s' does not match %s: %w", lines[2], operation_line_re, fileutil.ErrUnexpectedFormat))
+	} else {
+		expr_start, expr_end := op_details[2], op_details[3]
+		p.m.inspect_expr, err = ParseExpression(lines[2][expr_start:expr_end])
+		if err != nil {
+			// expression errors only know their column within the expression, so shift it to be within the line
+			errs.Add(fileutil.AtLine(fileutil.ShiftColumn(err, expr_start), block.FirstLine+2))
+		} else {
+			p.m.inspect_op = p.m.inspect_expr.Evaluate
 		}
+	}
 
-		i++
+	// get test condition
+	test, err := test_decoder.Decode(lines[3])
+	errs.Add(fileutil.AtLine(err, block.FirstLine+3))
+	if err == nil && test.Divisor == 0 {
+		errs.Add(fileutil.Errorf(block.FirstLine+3, lastValueColumn(lines[3]), "test is %w", ErrDivisionByZero))
+	}
+	p.m.divisible_test = test.Divisor
+
+	// get true and false cases, which are checked against the other monkeys later
+	true_action, err := true_decoder.Decode(lines[4])
+	errs.Add(fileutil.AtLine(err, block.FirstLine+4))
+	p.true_id = true_action.Dest
+	p.true_line = block.FirstLine + 4
+	p.true_ok = err == nil
+
+	false_action, err := false_decoder.Decode(lines[5])
+	errs.Add(fileutil.AtLine(err, block.FirstLine+5))
+	p.false_id = false_action.Dest
+	p.false_line = block.FirstLine + 5
+	p.false_ok = err == nil
+
+	return p
+}
 
-		// get operation
-		op_details := operation_line_re.FindAllStringSubmatch(lines[i], -1)
-		if len(op_details[0]) < 3 {
-			fmt.Printf("Unable to extract operation details from line '%s': %+v\n", lines[i], op_details)
-			return nil, err
-		}
+// ParseMonkeysFromInput reads every monkey, returning them in order of id (which is the order they take turns in)
+// Ids need not start at 0 or be contiguous; every problem with the input is reported at once, as a fileutil.ErrorList
+func ParseMonkeysFromInput(lines []string) ([]*Monkey, error) {
+	var errs fileutil.ErrorList
 
-		if strings.Compare(op_details[0][1], "*") == 0 {
-			if strings.Compare(op_details[0][2], "old") == 0 {
-				m.inspect_op = func(old uint64) (new uint64) { return old * old }
-			} else {
-				literal, err := strconv.ParseInt(op_details[0][2], 10, 64)
-				if err != nil {
-					fmt.Printf("Unable to extract operation literal from line '%s': %+v\n", lines[i], err)
-					return nil, err
-				}
+	// Initialize monkeys: each is described by a block of lines, separated by blank lines
+	blocks := fileutil.GroupBlocks(lines)
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no monkeys: %w", fileutil.ErrUnexpectedFormat)
+	}
+
+	parsed := []parsedMonkey{}
+	ids_unread := false // whether some monkey's id could not be read, so any missing destination may be that monkey
+	for _, block := range blocks {
+		if len(block.Lines) != MONKEY_BLOCK_LINES {
+			errs.Add(fileutil.Errorf(block.FirstLine, 0, "monkey has %d lines instead of %d: %w", len(block.Lines), MONKEY_BLOCK_LINES, fileutil.ErrUnexpectedFormat))
 
-				m.inspect_op = func(old uint64) (new uint64) { return old * uint64(literal) }
+			// the monkey is still known by its id, if it has one, so others throwing to it are not also reported
+			if id, err := id_decoder.Decode(block.Lines[0]); err == nil {
+				parsed = append(parsed, parsedMonkey{m: &Monkey{id: id.ID}, id_line: block.FirstLine, id_ok: true})
+			} else {
+				ids_unread = true
 			}
+			continue
+		}
+
+		if p := parseMonkey(block, &errs); p.id_ok {
+			parsed = append(parsed, p)
 		} else {
-			// addition
-			literal, err := strconv.ParseInt(op_details[0][2], 10, 64)
-			if err != nil {
-				fmt.Printf("Unable to extract operation literal from line '%s': %+v\n", lines[i], err)
-				return nil, err
-			}
+			ids_unread = true
+		}
+	}
 
-			m.inspect_op = func(old uint64) (new uint64) { return old + uint64(literal) }
+	// Item IDs follow the order of the input
+	num_items := 0
+	for _, p := range parsed {
+		p.m.reduced_items = NewItemQueue(len(p.items))
+		for _, worry := range p.items {
+			p.m.reduced_items.PushBack(Item{ID: num_items, Worry: worry})
+			num_items++
 		}
+	}
 
-		i++
+	// Monkeys take turns in order of id, which must be unique
+	sort.SliceStable(parsed, func(i, j int) bool { return parsed[i].m.id < parsed[j].m.id })
 
-		// get test condition
-		test_details := test_line_re.FindAllStringSubmatch(lines[i], -1)
-		if len(test_details[0]) < 2 {
-			fmt.Printf("Unable to extract test details from line '%s': %+v\n", lines[i], test_details)
-			return nil, err
+	indexes := make(map[int]int)
+	for i, p := range parsed {
+		if i > 0 && parsed[i-1].m.id == p.m.id {
+			errs.Add(fileutil.Errorf(p.id_line, lastValueColumn(lines[p.id_line-1]), "monkey %d is already defined on line %d", p.m.id, parsed[i-1].id_line))
+			continue
 		}
 
-		div_64, err := strconv.ParseInt(test_details[0][1], 10, 64)
-		if err != nil {
-			fmt.Printf("Unable to extract division literal from line '%s': %+v\n", lines[i], err)
-			return nil, err
+		indexes[p.m.id] = i
+	}
+
+	// Throws must go to another monkey which exists; with an id already reported as unreadable, only throws to itself are
+	monkeys := make([]*Monkey, len(parsed))
+	for i, p := range parsed {
+		var found bool
+		p.m.index = i
+		p.m.true_dest, found = indexes[p.true_id]
+		if p.true_ok {
+			errs.Add(checkDestination(p.m.id, p.true_id, found || ids_unread, p.true_line, lines[p.true_line-1]))
+		}
+		p.m.false_dest, found = indexes[p.false_id]
+		if p.false_ok {
+			errs.Add(checkDestination(p.m.id, p.false_id, found || ids_unread, p.false_line, lines[p.false_line-1]))
 		}
 
-		m.divisible_test = uint64(div_64)
+		monkeys[i] = p.m
+	}
+
+	if len(errs) > 0 {
+		// report problems in order of the input
+		sort.SliceStable(errs, func(i, j int) bool { return errorLine(errs[i]) < errorLine(errs[j]) })
+		return nil, errs
+	}
 
-		i++
+	return monkeys, nil
+}
 
-		// get true case
-		true_details := action_line_re.FindAllStringSubmatch(lines[i], -1)
-		if len(true_details[0]) < 3 {
-			fmt.Printf("Unable to extract true details from line '%s': %+v\n", lines[i], true_details)
-			return nil, err
+// checkDestination reports a problem with monkey id throwing to dest_id, if there is one
+func checkDestination(id, dest_id int, found bool, line_num int, line string) error {
+	if !found {
+		return fileutil.Errorf(line_num, lastValueColumn(line), "monkey %d throws to monkey %d, which does not exist", id, dest_id)
+	}
+	if dest_id == id {
+		return fileutil.Errorf(line_num, lastValueColumn(line), "monkey %d throws to itself", id)
+	}
+
+	return nil
+}
+
+// errorLine is the line a problem was found on, or 0 if it is not known
+func errorLine(err error) int {
+	var pe *fileutil.PositionError
+	if errors.As(err, &pe) {
+		return pe.Line
+	}
+
+	return 0
+}
+
+// ErrNotModular is returned when worry levels would need to be kept modulo something, but an operation does not allow it
+var ErrNotModular = errors.New("worry levels cannot be kept modulo a number")
+
+// SimulationOptions choose how MonkeyBusiness treats worry levels
+type SimulationOptions struct {
+	// Relief changes worry levels after every inspection; if not set, there is none
+	Relief ReliefPolicy
+	// BigWorry keeps worry levels as *big.Int, with no limit on their size; otherwise, they are uint64 and overflowing is an error
+	BigWorry bool
+	// FastForward extrapolates modular worry levels from where each item's rounds start repeating (see FastForward), rather than playing every round
+	FastForward bool
+	// Trace, if set, is called for every throw; every round is then played, even with FastForward
+	Trace func(ThrowEvent) error
+	// Aggregate sums up the monkeys' stats as the level of monkey business; if not set, it is the puzzle's TopKProduct(2)
+	Aggregate Aggregate
+}
+
+// MonkeyBusiness simulates the monkeys throwing items for the given rounds and reports what each monkey did, with the level of monkey business
+// A modular relief policy only gives the right answer when every operation is modular, which is checked
+func MonkeyBusiness(monkeys []*Monkey, rounds uint, opts SimulationOptions) (Report, error) {
+	if opts.Relief == nil {
+		opts.Relief = NoRelief{}
+	}
+	if opts.Aggregate == nil {
+		opts.Aggregate = TopKProduct(2)
+	}
+
+	divisors := make([]uint64, len(monkeys))
+	for _, m := range monkeys {
+		if len(m.throws_to) != len(monkeys) {
+			m.throws_to = make([]uint64, len(monkeys))
+		}
+		if m.divisible_test == 0 {
+			return Report{}, fmt.Errorf("monkey %d test: %w", m.id, ErrDivisionByZero)
 		}
+		divisors[m.index] = m.divisible_test
+	}
 
-		m.true_dest, err = strconv.Atoi(true_details[0][2])
-		if err != nil {
-			fmt.Printf("Unable to extract destination monkey id from line '%s': %+v\n", lines[i], err)
-			return nil, err
+	modulus, err := opts.Relief.Modulus(divisors)
+	if err != nil {
+		return Report{}, err
+	}
+
+	// any monkey could end up holding every item, so make room for that now rather than while throwing
+	num_items := 0
+	for _, m := range monkeys {
+		num_items += m.reduced_items.Len()
+	}
+	for _, m := range monkeys {
+		m.reduced_items.Grow(num_items)
+	}
+
+	// fast-forwarding can leave fewer rounds to be played
+	rounds_to_play := rounds
+
+	if modulus > 0 {
+		for _, m := range monkeys {
+			if !m.inspect_expr.IsModular() {
+				return Report{}, fmt.Errorf("monkey %d operation %v: %w (relief %s needs it; exact relief policies do not)", m.id, m.inspect_expr, ErrNotModular, opts.Relief)
+			}
+
+			// starting items may not be reduced yet
+			for i := 0; i < m.reduced_items.Len(); i++ {
+				item := m.reduced_items.At(i)
+				item.Worry %= modulus
+				m.reduced_items.Set(i, item)
+			}
 		}
+	}
 
-		i++
+	// Choose how worry levels are stored and changed by an inspection
+	// items are passed by value, so they do not need to be allocated on the heap
+	var inspect func(m *Monkey, item Item) (inspected Item, divisible bool, err error)
 
-		// get false case
-		false_details := action_line_re.FindAllStringSubmatch(lines[i], -1)
-		if len(false_details[0]) < 3 {
-			fmt.Printf("Unable to extract false details from line '%s': %+v\n", lines[i], false_details)
-			return nil, err
+	switch {
+	case opts.BigWorry:
+		for _, m := range monkeys {
+			for i := 0; i < m.reduced_items.Len(); i++ {
+				item := m.reduced_items.At(i)
+				if item.BigWorry == nil {
+					item.BigWorry = new(big.Int).SetUint64(item.Worry)
+					m.reduced_items.Set(i, item)
+				}
+			}
 		}
 
-		m.false_dest, err = strconv.Atoi(false_details[0][2])
-		if err != nil {
-			fmt.Printf("Unable to extract destination monkey id from line '%s': %+v\n", lines[i], err)
-			return nil, err
+		big_modulus := new(big.Int).SetUint64(modulus)
+		inspect = func(m *Monkey, item Item) (Item, bool, error) {
+			worry_level, err := m.inspect_expr.EvaluateBig(item.BigWorry)
+			if err != nil {
+				return item, false, err
+			}
+
+			if modulus > 0 {
+				// Mod rather than Rem, as subtraction can make it negative
+				worry_level = new(big.Int).Mod(worry_level, big_modulus)
+			} else {
+				worry_level, err = opts.Relief.RelieveBig(worry_level)
+				if err != nil {
+					return item, false, err
+				}
+			}
+
+			item.BigWorry = worry_level
+			remainder := new(big.Int).Rem(worry_level, new(big.Int).SetUint64(m.divisible_test))
+			return item, remainder.Sign() == 0, nil
+		}
+
+	case modulus > 0:
+		inspect = func(m *Monkey, item Item) (Item, bool, error) {
+			// reduce to remainder after modulus
+			item.Worry = m.inspect_expr.EvaluateMod(item.Worry, modulus)
+			return item, item.Worry%m.divisible_test == 0, nil
 		}
 
-		i++
+		// reduced worry levels are bounded, so items' rounds eventually repeat and need not all be played
+		if opts.FastForward && opts.Trace == nil {
+			if err := FastForward(monkeys, rounds, modulus); err != nil {
+				return Report{}, err
+			}
+			rounds_to_play = 0
+		}
 
-		// save it to the array
-		monkeys[m.id] = m
-	}
+	default:
+		inspect = func(m *Monkey, item Item) (Item, bool, error) {
+			worry_level, err := m.inspect_op(item.Worry)
+			if err != nil {
+				return item, false, err
+			}
 
-	return monkeys, nil
-}
+			item.Worry, err = opts.Relief.Relieve(worry_level)
+			if err != nil {
+				return item, false, err
+			}
 
-func MonkeyBusiness(monkeys []*Monkey, rounds uint, undamaged_bonus bool) uint64 {
-	// Compute product of all divisors
-	var divisor_product uint64
-	divisor_product = 1
-	for _, m := range monkeys {
-		divisor_product *= m.divisible_test
+			return item, item.Worry%m.divisible_test == 0, nil
+		}
 	}
 
 	// Emulate monkeys throwing for X rounds
 	var round uint
-	for round = 1; round <= rounds; round++ {
+	for round = 1; round <= rounds_to_play; round++ {
 		for _, m := range monkeys {
 			num_items := m.reduced_items.Len()
 			for i := 0; i < num_items; i++ {
 				// monkey inspects item
-				item := m.reduced_items.Front()
-				worry_level := item.Value.(uint64)
-				m.reduced_items.Remove(item)
+				item := m.reduced_items.PopFront()
 				m.inspections_performed++
 
-				// worry level goes through operation
-				worry_level = m.inspect_op(worry_level)
-
-				// monkey gets bored with item; worry level may be divided by 3
-				if undamaged_bonus {
-					worry_level /= 3
-				} else {
-					// reduce to remainder after product
-					worry_level %= divisor_product
+				// worry level goes through operation, then monkey gets bored with item and worry level is relieved
+				// then test worry level against monkey's condition
+				inspected, divisible, err := inspect(m, item)
+				if err != nil {
+					return Report{}, fmt.Errorf("round %d, monkey %d, item %d: %w", round, m.id, item.ID, err)
 				}
 
-				// test worry level against monkey's condition
 				dest_monkey := m.false_dest
-				if worry_level%m.divisible_test == 0 {
+				if divisible {
 					dest_monkey = m.true_dest
 				}
+				m.throws_to[dest_monkey]++
+
+				if opts.Trace != nil {
+					event := ThrowEvent{Round: round, Item: item.ID, From: m.id, To: monkeys[dest_monkey].id, WorryBefore: item.WorryLevel(), WorryAfter: inspected.WorryLevel()}
+					if err := opts.Trace(event); err != nil {
+						return Report{}, err
+					}
+				}
 
 				// throw item
-				monkeys[dest_monkey].reduced_items.PushBack(worry_level)
+				monkeys[dest_monkey].reduced_items.PushBack(inspected)
 			}
 		}
 	}
 
-	// Determine two most active monkeys
-	var most, second_most uint64
-	most = 0
-	second_most = 0
-
+	// Report what each monkey did
+	report := Report{Rounds: rounds, Monkeys: make([]MonkeyStats, len(monkeys))}
 	for _, m := range monkeys {
-		if m.inspections_performed > most {
-			second_most = most
-			most = m.inspections_performed
-		} else if m.inspections_performed > second_most {
-			second_most = m.inspections_performed
+		report.Monkeys[m.index] = MonkeyStats{
+			ID:          m.id,
+			Inspections: m.inspections_performed,
+			ItemsHeld:   m.reduced_items.Len(),
+			ThrowsTo:    make(map[int]uint64, len(monkeys)),
+		}
+		for dest, count := range m.throws_to {
+			report.Monkeys[m.index].ThrowsTo[monkeys[dest].id] = count
 		}
 	}
 
 	// Calculate the level of monkey business
-	return most * second_most
+	report.Business, err = opts.Aggregate(report.Monkeys)
+	return report, err
 }
 
-func main() {
-	// Get input from file
-	lines, err := fileutil.GetLinesFromFile("input.txt")
+// CopyMonkeys deep-copies every monkey, so a simulation can start again from the same initial state
+func CopyMonkeys(monkeys []*Monkey) []*Monkey {
+	copied := make([]*Monkey, len(monkeys))
+	for i, m := range monkeys {
+		copied[i] = m.DeepCopy()
+	}
+
+	return copied
+}
+
+// solvePart runs a fresh copy of the monkeys for the part's rounds, exporting a trace of every throw and a report of every monkey if asked for
+func solvePart(initial_monkeys []*Monkey, params solver.Params, part int) (answer any, err error) {
+	rounds, err := params.Uint(fmt.Sprintf("part%d_rounds", part))
+	if err != nil {
+		return nil, err
+	}
+
+	relief, err := params.String(fmt.Sprintf("part%d_relief", part))
+	if err != nil {
+		return nil, err
+	}
+
+	opts := SimulationOptions{}
+
+	opts.Relief, err = ParseReliefPolicy(relief)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.BigWorry, err = params.Bool("big_worry")
+	if err != nil {
+		return nil, err
+	}
+
+	opts.FastForward, err = params.Bool("fast_forward")
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate_name, err := params.String("aggregate")
+	if err != nil {
+		return nil, err
+	}
+
+	top_k, err := params.Int("top_k")
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Aggregate, err = ParseAggregate(aggregate_name, top_k)
+	if err != nil {
+		return nil, err
+	}
+
+	export_format, err := params.String("export_format")
+	if err != nil {
+		return nil, err
+	}
+
+	trace_name, err := params.String("trace")
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	initial_monkeys, err := ParseMonkeysFromInput(lines)
+	report_name, err := params.String("report")
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	// Part 1
-	// worry is divided by 3 each inspection, 20 rounds
-	monkeys := make([]*Monkey, len(initial_monkeys))
-	for i, m := range initial_monkeys {
-		monkeys[i] = m.DeepCopy()
+	// record every throw
+	var tw *TraceWriter
+	if len(trace_name) > 0 {
+		// assigned rather than declared, so closeFile sets the returned err
+		var f *os.File
+		f, err = createPartFile(trace_name, part)
+		if err != nil {
+			return nil, err
+		}
+		defer closeFile(f, &err)
+
+		tw, err = NewTraceWriter(f, export_format)
+		if err != nil {
+			return nil, err
+		}
+		opts.Trace = tw.Write
 	}
 
-	monkey_business_level := MonkeyBusiness(monkeys, 20, true)
-	fmt.Printf("\nPart 1 answer: %v\n\n", monkey_business_level)
+	report, err := MonkeyBusiness(CopyMonkeys(initial_monkeys), rounds, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if tw != nil {
+		if err := tw.Flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	// record what every monkey did
+	if len(report_name) > 0 {
+		var f *os.File
+		f, err = createPartFile(report_name, part)
+		if err != nil {
+			return nil, err
+		}
+		defer closeFile(f, &err)
+
+		if err := WriteReport(f, report, export_format); err != nil {
+			return nil, err
+		}
+	}
+
+	return report.Business, nil
+}
+
+func main() {
+	// other modes than solving are chosen by a command before any flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case BATCH_COMMAND:
+			os.Exit(RunBatch(os.Args[2:], os.Stdout, os.Stderr))
+		case GENERATE_COMMAND:
+			os.Exit(RunGenerate(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
+	solver.Run(solver.Day[[]*Monkey]{
+		Number: 11,
+		Params: solver.Params{
+			"part1_rounds": "20",
+			"part2_rounds": "10000",
+			// how worry levels go down after each inspection (see ParseReliefPolicy)
+			"part1_relief": RELIEF_DIVIDE + ":3",
+			"part2_relief": RELIEF_PRODUCT,
+			// keep worry levels of any size, e.g. to get exact part 2 worry levels with part2_relief=none
+			"big_worry": "false",
+			// skip ahead once items' rounds start repeating; only used with a modular relief policy
+			"fast_forward": "true",
+			// how to sum up the monkeys' inspections as the answer: AGGREGATE_PRODUCT of the top_k monkeys, or AGGREGATE_SUM
+			"aggregate": AGGREGATE_PRODUCT,
+			"top_k":     "2",
+			// files to record every throw and every monkey's stats in, one per part (e.g. trace_part1.csv for trace.csv); empty for none
+			"trace":         "",
+			"report":        "",
+			"export_format": EXPORT_CSV,
+		},
+
+		// Get input from file
+		Parse: func(input_name string, params solver.Params) ([]*Monkey, error) {
+			lines, err := fileutil.GetLinesFromFile(input_name)
+			if err != nil {
+				return nil, err
+			}
 
-	// Part 2
-	// Starting again from the initial state in your puzzle input, what is the level of monkey business after 10000 rounds?
-	monkey_business_level = MonkeyBusiness(initial_monkeys, 10000, false)
-	fmt.Printf("\nPart 2 answer: %v\n", monkey_business_level)
+			initial_monkeys, err := ParseMonkeysFromInput(lines)
+			return initial_monkeys, fileutil.WithFile(err, fileutil.InputDisplayName(input_name))
+		},
+
+		Parts: []solver.Part[[]*Monkey]{
+			// Part 1
+			// worry is divided by 3 each inspection, 20 rounds
+			func(initial_monkeys []*Monkey, params solver.Params) (any, error) {
+				return solvePart(initial_monkeys, params, 1)
+			},
+
+			// Part 2
+			// Starting again from the initial state in your puzzle input, what is the level of monkey business after 10000 rounds?
+			func(initial_monkeys []*Monkey, params solver.Params) (any, error) {
+				return solvePart(initial_monkeys, params, 2)
+			},
+		},
+	})
 }
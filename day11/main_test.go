@@ -0,0 +1,231 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+)
+
+// loadMonkeys parses the named puzzle input, failing the test if it cannot
+func loadMonkeys(tb testing.TB, name string) []*Monkey {
+	tb.Helper()
+
+	lines, err := fileutil.GetLinesFromFile(name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	monkeys, err := ParseMonkeysFromInput(lines)
+	if err != nil {
+		tb.Fatal(fileutil.WithFile(err, name))
+	}
+
+	return monkeys
+}
+
+// troop is the example input's first two monkeys, changed by each replacement of old with new
+func troop(replacements ...string) []string {
+	text := `Monkey 0:
+  Starting items: 79, 98
+  Operation: new = old * 19
+  Test: divisible by 23
+    If true: throw to monkey 1
+    If false: throw to monkey 1
+
+Monkey 1:
+  Starting items: 54, 65, 75, 74
+  Operation: new = old + 6
+  Test: divisible by 19
+    If true: throw to monkey 0
+    If false: throw to monkey 0`
+
+	for i := 0; i+1 < len(replacements); i += 2 {
+		text = strings.Replace(text, replacements[i], replacements[i+1], 1)
+	}
+
+	return strings.Split(text, "\n")
+}
+
+func TestParseMonkeysFromInputErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string // every problem, one per line
+	}{
+		{"valid", troop(), ""},
+		{
+			"short block keeps its id",
+			troop("  Test: divisible by 19\n", ""),
+			"8: monkey has 5 lines instead of 6: unexpected input format",
+		},
+		{
+			"every problem at once",
+			troop("54, 65", "54, x", "old + 6", "old + (6", "divisible by 23", "divisible by 0"),
+			"4:22: test is division by zero\n9:23: items: strconv.ParseUint: parsing \"x\": invalid syntax\n10:26: '(' is never closed",
+		},
+		{
+			"missing and self destinations",
+			troop("If true: throw to monkey 1", "If true: throw to monkey 7", "If false: throw to monkey 0", "If false: throw to monkey 1"),
+			"5:30: monkey 0 throws to monkey 7, which does not exist\n13:31: monkey 1 throws to itself",
+		},
+		{
+			"duplicate id",
+			troop("Monkey 1:", "Monkey 0:"),
+			"5:30: monkey 0 throws to monkey 1, which does not exist\n6:31: monkey 0 throws to monkey 1, which does not exist\n8:8: monkey 0 is already defined on line 1\n12:30: monkey 0 throws to itself\n13:31: monkey 0 throws to itself",
+		},
+		{
+			"unreadable id is reported once",
+			troop("Monkey 1:", "Monkey x:"),
+			"8: 'Monkey x:' does not match ^Monkey (?P<id>\\d+):$: unexpected input format",
+		},
+		{
+			"unreadable id still finds throws to itself",
+			troop("Monkey 1:", "Monkey x:", "If true: throw to monkey 1", "If true: throw to monkey 0"),
+			"5:30: monkey 0 throws to itself\n8: 'Monkey x:' does not match ^Monkey (?P<id>\\d+):$: unexpected input format",
+		},
+		{
+			"short block with an unreadable id",
+			troop("Monkey 1:\n  Starting items: 54, 65, 75, 74\n", "Monkey x:\n"),
+			"8: monkey has 5 lines instead of 6: unexpected input format",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ParseMonkeysFromInput(test.lines)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+
+		if got != test.want {
+			t.Errorf("%s: got errors\n%s\nwant\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+// heldItems is every item's worry level, by the id of the monkey holding it and then the item's id
+// Fast-forwarding does not keep the order of items within a monkey's queue, so it is not compared
+func heldItems(monkeys []*Monkey) map[int]map[int]string {
+	held := make(map[int]map[int]string, len(monkeys))
+	for _, m := range monkeys {
+		held[m.id] = make(map[int]string, m.reduced_items.Len())
+		for i := 0; i < m.reduced_items.Len(); i++ {
+			item := m.reduced_items.At(i)
+			held[m.id][item.ID] = fmt.Sprint(item.WorryLevel())
+		}
+	}
+
+	return held
+}
+
+func TestFastForward(t *testing.T) {
+	for _, name := range []string{"example_input.txt", "input.txt"} {
+		initial_monkeys := loadMonkeys(t, name)
+
+		for _, rounds := range []uint{1, 7, 12345, 100000} {
+			// playing every round is what the other modes must match
+			want_monkeys := CopyMonkeys(initial_monkeys)
+			want, err := MonkeyBusiness(want_monkeys, rounds, SimulationOptions{Relief: ProductRelief{}})
+			if err != nil {
+				t.Fatalf("%s, %d rounds: %v", name, rounds, err)
+			}
+
+			for _, opts := range []SimulationOptions{
+				{Relief: ProductRelief{}, FastForward: true},
+				{Relief: ProductRelief{}, BigWorry: true},
+			} {
+				monkeys := CopyMonkeys(initial_monkeys)
+				got, err := MonkeyBusiness(monkeys, rounds, opts)
+				if err != nil {
+					t.Errorf("%s, %d rounds, %+v: %v", name, rounds, opts, err)
+					continue
+				}
+
+				if got.Business.Cmp(want.Business) != 0 {
+					t.Errorf("%s, %d rounds, %+v: got monkey business %v, want %v", name, rounds, opts, got.Business, want.Business)
+				}
+				if !reflect.DeepEqual(got.Monkeys, want.Monkeys) {
+					t.Errorf("%s, %d rounds, %+v: got monkeys %+v, want %+v", name, rounds, opts, got.Monkeys, want.Monkeys)
+				}
+				if got_held, want_held := heldItems(monkeys), heldItems(want_monkeys); !reflect.DeepEqual(got_held, want_held) {
+					t.Errorf("%s, %d rounds, %+v: monkeys hold %v, want %v", name, rounds, opts, got_held, want_held)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkItemQueue moves an item from the front to the back of a queue, as monkeys do when throwing
+func BenchmarkItemQueue(b *testing.B) {
+	const SIZE = 100
+	q := NewItemQueue(SIZE)
+	for i := 0; i < SIZE; i++ {
+		q.PushBack(Item{ID: i, Worry: uint64(i)})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		item := q.PopFront()
+		item.Worry++
+		q.PushBack(item)
+	}
+}
+
+// BenchmarkContainerList is BenchmarkItemQueue for container/list, which monkeys used to hold their items in
+func BenchmarkContainerList(b *testing.B) {
+	const SIZE = 100
+	l := list.New()
+	for i := 0; i < SIZE; i++ {
+		l.PushBack(Item{ID: i, Worry: uint64(i)})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := l.Front()
+		l.Remove(e)
+		item := e.Value.(Item)
+		item.Worry++
+		l.PushBack(item)
+	}
+}
+
+// benchmarkMonkeyBusiness simulates a fresh copy of the input's monkeys each run, playing every round and then fast-forwarding; copying is not timed
+func benchmarkMonkeyBusiness(b *testing.B, rounds uint) {
+	initial_monkeys := loadMonkeys(b, "input.txt")
+
+	for _, fast_forward := range []bool{false, true} {
+		name := "full"
+		if fast_forward {
+			name = "fast_forward"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			opts := SimulationOptions{Relief: ProductRelief{}, FastForward: fast_forward}
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				monkeys := CopyMonkeys(initial_monkeys)
+				b.StartTimer()
+
+				if _, err := MonkeyBusiness(monkeys, rounds, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMonkeyBusiness10k(b *testing.B) {
+	benchmarkMonkeyBusiness(b, 10000)
+}
+
+func BenchmarkMonkeyBusiness1M(b *testing.B) {
+	benchmarkMonkeyBusiness(b, 1000000)
+}
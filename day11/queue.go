@@ -0,0 +1,74 @@
+package main
+
+// ItemQueue is a first-in first-out queue of items, kept in a ring buffer so pushing and popping do not allocate once it is big enough
+type ItemQueue struct {
+	items []Item
+	head  int // index in items of the front of the queue
+	size  int
+}
+
+func NewItemQueue(capacity int) *ItemQueue {
+	return &ItemQueue{items: make([]Item, capacity)}
+}
+
+func (q *ItemQueue) Len() int {
+	return q.size
+}
+
+// Grow makes room for at least n items in total, so the queue will not need to allocate again until it holds more
+func (q *ItemQueue) Grow(n int) {
+	if n <= len(q.items) {
+		return
+	}
+
+	// unwrap the ring, front first
+	items := make([]Item, n)
+	for i := 0; i < q.size; i++ {
+		items[i] = q.At(i)
+	}
+
+	q.items = items
+	q.head = 0
+}
+
+func (q *ItemQueue) PushBack(item Item) {
+	if q.size == len(q.items) {
+		q.Grow(2*len(q.items) + 1)
+	}
+
+	q.items[(q.head+q.size)%len(q.items)] = item
+	q.size++
+}
+
+// PopFront removes and returns the item at the front; the queue must not be empty
+func (q *ItemQueue) PopFront() Item {
+	if q.size == 0 {
+		panic("PopFront of empty ItemQueue")
+	}
+
+	item := q.items[q.head]
+	q.items[q.head] = Item{} // do not keep *big.Int worry levels alive
+	q.head = (q.head + 1) % len(q.items)
+	q.size--
+
+	return item
+}
+
+// At returns the i-th item from the front
+func (q *ItemQueue) At(i int) Item {
+	return q.items[(q.head+i)%len(q.items)]
+}
+
+// Set replaces the i-th item from the front
+func (q *ItemQueue) Set(i int, item Item) {
+	q.items[(q.head+i)%len(q.items)] = item
+}
+
+// Clear empties the queue, keeping its room for items
+func (q *ItemQueue) Clear() {
+	for i := range q.items {
+		q.items[i] = Item{}
+	}
+	q.head = 0
+	q.size = 0
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// ReliefPolicy decides how worry levels go down after a monkey inspects an item, keeping them manageable
+type ReliefPolicy interface {
+	// Modulus is what worry levels are kept modulo after every inspection, or 0 if the policy is exact and uses Relieve instead
+	// A modulus must keep the remainder for every monkey's divisor, so it is given all of them
+	Modulus(divisors []uint64) (uint64, error)
+	// Relieve and RelieveBig change an exact worry level after an inspection
+	Relieve(worry uint64) (uint64, error)
+	RelieveBig(worry *big.Int) (*big.Int, error)
+	String() string
+}
+
+// Names of relief policies, as given to ParseReliefPolicy
+const (
+	RELIEF_NONE       = "none"    // worry levels only go up
+	RELIEF_DIVIDE     = "divide"  // "divide:N" divides by N, rounding down; the puzzle's part 1 is "divide:3"
+	RELIEF_PRODUCT    = "product" // kept modulo the product of every monkey's divisor; the puzzle's part 2
+	RELIEF_LCM        = "lcm"     // kept modulo the least common multiple of every monkey's divisor
+	RELIEF_EXPRESSION = "expr"    // "expr:E" sets the worry level to expression E of the old one, e.g. "expr:old / 2 + 1"
+)
+
+// exactRelief is embedded by policies which do not keep worry levels modulo anything
+type exactRelief struct{}
+
+func (exactRelief) Modulus(_ []uint64) (uint64, error) { return 0, nil }
+
+// modularRelief is embedded by policies which keep worry levels modulo something, and so never relieve exact worry levels
+type modularRelief struct{}
+
+func (modularRelief) Relieve(worry uint64) (uint64, error)        { return worry, nil }
+func (modularRelief) RelieveBig(worry *big.Int) (*big.Int, error) { return worry, nil }
+
+// NoRelief leaves worry levels as they are
+type NoRelief struct {
+	exactRelief
+}
+
+func (NoRelief) Relieve(worry uint64) (uint64, error)        { return worry, nil }
+func (NoRelief) RelieveBig(worry *big.Int) (*big.Int, error) { return worry, nil }
+func (NoRelief) String() string                              { return RELIEF_NONE }
+
+// DivideRelief divides worry levels by N, rounding down
+type DivideRelief struct {
+	exactRelief
+	N uint64
+}
+
+func (d DivideRelief) Relieve(worry uint64) (uint64, error) { return worry / d.N, nil }
+func (d DivideRelief) RelieveBig(worry *big.Int) (*big.Int, error) {
+	return new(big.Int).Quo(worry, new(big.Int).SetUint64(d.N)), nil
+}
+func (d DivideRelief) String() string { return fmt.Sprintf("%s:%d", RELIEF_DIVIDE, d.N) }
+
+// ProductRelief keeps worry levels modulo the product of every monkey's divisor
+type ProductRelief struct {
+	modularRelief
+}
+
+func (ProductRelief) Modulus(divisors []uint64) (uint64, error) {
+	var product uint64
+	product = 1
+	for _, d := range divisors {
+		hi, lo := bits.Mul64(product, d)
+		if hi != 0 {
+			return 0, fmt.Errorf("product of divisors: %w", ErrWorryOverflow)
+		}
+		product = lo
+	}
+
+	return product, nil
+}
+func (ProductRelief) String() string { return RELIEF_PRODUCT }
+
+// LCMRelief keeps worry levels modulo the least common multiple of every monkey's divisor, which is smaller than their product when divisors share factors
+type LCMRelief struct {
+	modularRelief
+}
+
+func (LCMRelief) Modulus(divisors []uint64) (uint64, error) {
+	var lcm uint64
+	lcm = 1
+	for _, d := range divisors {
+		// lcm(a, b) = a / gcd(a, b) * b
+		a, b := lcm, d
+		for b != 0 {
+			a, b = b, a%b
+		}
+
+		hi, lo := bits.Mul64(lcm/a, d)
+		if hi != 0 {
+			return 0, fmt.Errorf("least common multiple of divisors: %w", ErrWorryOverflow)
+		}
+		lcm = lo
+	}
+
+	return lcm, nil
+}
+func (LCMRelief) String() string { return RELIEF_LCM }
+
+// ExpressionRelief sets worry levels to an expression of the old worry level
+type ExpressionRelief struct {
+	exactRelief
+	Expr Expression
+}
+
+func (e ExpressionRelief) Relieve(worry uint64) (uint64, error) { return e.Expr.Evaluate(worry) }
+func (e ExpressionRelief) RelieveBig(worry *big.Int) (*big.Int, error) {
+	return e.Expr.EvaluateBig(worry)
+}
+func (e ExpressionRelief) String() string { return fmt.Sprintf("%s:%v", RELIEF_EXPRESSION, e.Expr) }
+
+// ReliefFunc is a custom exact relief policy; in the big worry mode, it fails on worry levels which do not fit in uint64
+type ReliefFunc func(worry uint64) (uint64, error)
+
+func (f ReliefFunc) Modulus(_ []uint64) (uint64, error)   { return 0, nil }
+func (f ReliefFunc) Relieve(worry uint64) (uint64, error) { return f(worry) }
+func (f ReliefFunc) RelieveBig(worry *big.Int) (*big.Int, error) {
+	if !worry.IsUint64() {
+		return nil, fmt.Errorf("custom relief of %v: %w", worry, ErrWorryOverflow)
+	}
+
+	relieved, err := f(worry.Uint64())
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetUint64(relieved), nil
+}
+func (f ReliefFunc) String() string { return "custom" }
+
+// ParseReliefPolicy returns the relief policy described by text: one of the RELIEF_ names, with ":" and its argument for those which take one
+func ParseReliefPolicy(text string) (ReliefPolicy, error) {
+	name, arg, has_arg := strings.Cut(text, ":")
+
+	switch {
+	case name == RELIEF_NONE && !has_arg:
+		return NoRelief{}, nil
+
+	case name == RELIEF_PRODUCT && !has_arg:
+		return ProductRelief{}, nil
+
+	case name == RELIEF_LCM && !has_arg:
+		return LCMRelief{}, nil
+
+	case name == RELIEF_DIVIDE && has_arg:
+		n, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("relief '%s' must divide by a positive whole number", text)
+		}
+		return DivideRelief{N: n}, nil
+
+	case name == RELIEF_EXPRESSION && has_arg:
+		expr, err := ParseExpression(arg)
+		if err != nil {
+			return nil, fmt.Errorf("relief '%s': %w", text, err)
+		}
+		return ExpressionRelief{Expr: expr}, nil
+	}
+
+	return nil, fmt.Errorf("unknown relief policy '%s' (expected %s, %s:N, %s, %s or %s:E)", text, RELIEF_NONE, RELIEF_DIVIDE, RELIEF_PRODUCT, RELIEF_LCM, RELIEF_EXPRESSION)
+}
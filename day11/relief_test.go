@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestModulus(t *testing.T) {
+	tests := []struct {
+		divisors []uint64
+		product  uint64
+		lcm      uint64
+	}{
+		{divisors: []uint64{}, product: 1, lcm: 1},
+		{divisors: []uint64{1}, product: 1, lcm: 1},
+		{divisors: []uint64{2, 3, 5}, product: 30, lcm: 30},
+		{divisors: []uint64{4, 6}, product: 24, lcm: 12},
+		{divisors: []uint64{7, 7}, product: 49, lcm: 7},
+		{divisors: []uint64{6, 10, 15}, product: 900, lcm: 30},
+		{divisors: []uint64{12, 18, 8, 9}, product: 15552, lcm: 72},
+		// the product overflows, but the divisors share every factor
+		{divisors: []uint64{1 << 32, 1 << 32, 1 << 16}, product: 0, lcm: 1 << 32},
+	}
+
+	for _, test := range tests {
+		product, err := ProductRelief{}.Modulus(test.divisors)
+		switch {
+		case test.product == 0 && !errors.Is(err, ErrWorryOverflow):
+			t.Errorf("%v: got product %d (error %v), want %v", test.divisors, product, err, ErrWorryOverflow)
+		case test.product != 0 && (err != nil || product != test.product):
+			t.Errorf("%v: got product %d (error %v), want %d", test.divisors, product, err, test.product)
+		}
+
+		lcm, err := LCMRelief{}.Modulus(test.divisors)
+		if err != nil || lcm != test.lcm {
+			t.Errorf("%v: got lcm %d (error %v), want %d", test.divisors, lcm, err, test.lcm)
+		}
+	}
+
+	if lcm, err := (LCMRelief{}).Modulus([]uint64{1 << 63, 3}); !errors.Is(err, ErrWorryOverflow) {
+		t.Errorf("got lcm %d (error %v) of 2^63 and 3, want %v", lcm, err, ErrWorryOverflow)
+	}
+}
+
+func TestParseReliefPolicy(t *testing.T) {
+	tests := []struct {
+		text     string
+		want     string // the policy's String()
+		modulus  uint64 // for divisors 4 and 6
+		relieved uint64 // an exact worry level of 10 after relief
+	}{
+		{text: "none", want: "none", relieved: 10},
+		{text: "divide:3", want: "divide:3", relieved: 3},
+		{text: "divide:1", want: "divide:1", relieved: 10},
+		{text: "product", want: "product", modulus: 24},
+		{text: "lcm", want: "lcm", modulus: 12},
+		{text: "expr:old / 2 + 1", want: "expr:((old / 2) + 1)", relieved: 6},
+		{text: "expr:old % 4 * (old - 7)", want: "expr:((old % 4) * (old - 7))", relieved: 6},
+		{text: "expr:5", want: "expr:5", relieved: 5},
+	}
+
+	for _, test := range tests {
+		policy, err := ParseReliefPolicy(test.text)
+		if err != nil {
+			t.Errorf("'%s': unexpected error %v", test.text, err)
+			continue
+		}
+
+		if got := policy.String(); got != test.want {
+			t.Errorf("'%s': got policy %s, want %s", test.text, got, test.want)
+		}
+
+		modulus, err := policy.Modulus([]uint64{4, 6})
+		if err != nil || modulus != test.modulus {
+			t.Errorf("'%s': got modulus %d (error %v), want %d", test.text, modulus, err, test.modulus)
+		}
+		if modulus > 0 {
+			continue
+		}
+
+		if relieved, err := policy.Relieve(10); err != nil || relieved != test.relieved {
+			t.Errorf("'%s': relieved 10 to %d (error %v), want %d", test.text, relieved, err, test.relieved)
+		}
+	}
+}
+
+func TestParseReliefPolicyErrors(t *testing.T) {
+	for _, text := range []string{
+		"", "divide", "divide:", "divide:0", "divide:-1", "divide:x", "divide:3:4",
+		"none:1", "product:2", "lcm:", "expr", "expr:", "expr:old +", "expr:new", "modulo", "Product",
+	} {
+		if policy, err := ParseReliefPolicy(text); err == nil {
+			t.Errorf("'%s': got policy %v, want an error", text, policy)
+		}
+	}
+}
+
+func TestLCMMatchesProduct(t *testing.T) {
+	for _, name := range []string{"example_input.txt", "input.txt"} {
+		initial_monkeys := loadMonkeys(t, name)
+
+		want, err := MonkeyBusiness(CopyMonkeys(initial_monkeys), 10000, SimulationOptions{Relief: ProductRelief{}})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		got, err := MonkeyBusiness(CopyMonkeys(initial_monkeys), 10000, SimulationOptions{Relief: LCMRelief{}})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if got.Business.Cmp(want.Business) != 0 {
+			t.Errorf("%s: got monkey business %v with lcm relief, want %v as with product relief", name, got.Business, want.Business)
+		}
+	}
+}
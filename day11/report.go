@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+// MonkeyStats describe what one monkey did over a simulation
+type MonkeyStats struct {
+	ID          int            `json:"id"`
+	Inspections uint64         `json:"inspections"`
+	ItemsHeld   int            `json:"items_held"` // at the end of the simulation
+	ThrowsTo    map[int]uint64 `json:"throws_to"`  // by destination monkey id
+}
+
+// Report is the outcome of a simulation: every monkey's stats, and the level of monkey business chosen to sum them up
+type Report struct {
+	Rounds   uint
+	Monkeys  []MonkeyStats // in order of monkey id
+	Business *big.Int
+}
+
+// Aggregate sums up the monkeys' stats as a single level of monkey business
+type Aggregate func(stats []MonkeyStats) (*big.Int, error)
+
+// Aggregates which can be chosen by name
+const (
+	AGGREGATE_PRODUCT = "product" // product of the top K inspection counts
+	AGGREGATE_SUM     = "sum"     // total inspections by all monkeys
+)
+
+// TopKProduct multiplies the inspection counts of the k most active monkeys; the puzzle's monkey business is TopKProduct(2)
+func TopKProduct(k int) Aggregate {
+	return func(stats []MonkeyStats) (*big.Int, error) {
+		if k < 1 || k > len(stats) {
+			return nil, fmt.Errorf("cannot take the top %d of %d monkeys", k, len(stats))
+		}
+
+		inspections := make([]uint64, len(stats))
+		for i, s := range stats {
+			inspections[i] = s.Inspections
+		}
+		sort.Slice(inspections, func(i, j int) bool { return inspections[i] > inspections[j] })
+
+		// with enough rounds, the product does not fit in uint64
+		product := big.NewInt(1)
+		for _, count := range inspections[:k] {
+			product.Mul(product, new(big.Int).SetUint64(count))
+		}
+
+		return product, nil
+	}
+}
+
+// SumInspections adds up every monkey's inspection count
+func SumInspections(stats []MonkeyStats) (*big.Int, error) {
+	sum := new(big.Int)
+	for _, s := range stats {
+		sum.Add(sum, new(big.Int).SetUint64(s.Inspections))
+	}
+
+	return sum, nil
+}
+
+// ParseAggregate returns the aggregate with the given name; top_k is only used by AGGREGATE_PRODUCT
+func ParseAggregate(name string, top_k int) (Aggregate, error) {
+	switch name {
+	case AGGREGATE_PRODUCT:
+		return TopKProduct(top_k), nil
+	case AGGREGATE_SUM:
+		return SumInspections, nil
+	}
+
+	return nil, fmt.Errorf("unknown aggregate '%s' (expected %s or %s)", name, AGGREGATE_PRODUCT, AGGREGATE_SUM)
+}
+
+// WriteReport writes one record per monkey in one of the export formats
+func WriteReport(w io.Writer, report Report, format string) error {
+	if err := checkExportFormat(format); err != nil {
+		return err
+	}
+
+	if format == EXPORT_JSON {
+		e := json.NewEncoder(w)
+		for _, s := range report.Monkeys {
+			if err := e.Encode(s); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// one column of throws for each destination monkey
+	csv_w := csv.NewWriter(w)
+	header := []string{"id", "inspections", "items_held"}
+	for _, dest := range report.Monkeys {
+		header = append(header, fmt.Sprintf("throws_to_%d", dest.ID))
+	}
+	if err := csv_w.Write(header); err != nil {
+		return err
+	}
+
+	for _, s := range report.Monkeys {
+		record := []string{strconv.Itoa(s.ID), strconv.FormatUint(s.Inspections, 10), strconv.Itoa(s.ItemsHeld)}
+		for _, dest := range report.Monkeys {
+			record = append(record, strconv.FormatUint(s.ThrowsTo[dest.ID], 10))
+		}
+		if err := csv_w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csv_w.Flush()
+	return csv_w.Error()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// exampleReport is the example after 20 rounds, as at the end of the puzzle's part 1 walkthrough
+func exampleReport(t *testing.T) Report {
+	t.Helper()
+
+	report, err := MonkeyBusiness(loadMonkeys(t, "example_input.txt"), 20, SimulationOptions{Relief: DivideRelief{N: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return report
+}
+
+func TestAggregates(t *testing.T) {
+	stats := exampleReport(t).Monkeys
+
+	tests := []struct {
+		name      string
+		aggregate Aggregate
+		want      int64 // 0 for an error
+	}{
+		{name: "top 1", aggregate: TopKProduct(1), want: 105},
+		{name: "top 2", aggregate: TopKProduct(2), want: 10605},
+		{name: "top 4", aggregate: TopKProduct(4), want: 101 * 95 * 7 * 105},
+		{name: "top 0", aggregate: TopKProduct(0)},
+		{name: "top -1", aggregate: TopKProduct(-1)},
+		{name: "top 5 of 4", aggregate: TopKProduct(5)},
+		{name: "sum", aggregate: SumInspections, want: 101 + 95 + 7 + 105},
+	}
+
+	for _, test := range tests {
+		got, err := test.aggregate(stats)
+
+		switch {
+		case test.want == 0 && err == nil:
+			t.Errorf("%s: got %v, want an error", test.name, got)
+		case test.want != 0 && (err != nil || got.Cmp(big.NewInt(test.want)) != 0):
+			t.Errorf("%s: got %v (error %v), want %d", test.name, got, err, test.want)
+		}
+	}
+
+	// products too large for uint64 are exact
+	huge := []MonkeyStats{{Inspections: 1 << 40}, {Inspections: 1 << 40}}
+	want := new(big.Int).Lsh(big.NewInt(1), 80)
+	if got, err := TopKProduct(2)(huge); err != nil || got.Cmp(want) != 0 {
+		t.Errorf("got %v (error %v) for the product of 2^40 and 2^40, want %v", got, err, want)
+	}
+
+	if got, err := SumInspections(nil); err != nil || got.Sign() != 0 {
+		t.Errorf("got %v (error %v) for the sum of no monkeys, want 0", got, err)
+	}
+
+	if _, err := ParseAggregate("max", 2); err == nil {
+		t.Error("got no error for an unknown aggregate")
+	}
+}
+
+func TestWriteReportCSV(t *testing.T) {
+	report := exampleReport(t)
+
+	var sb strings.Builder
+	if err := WriteReport(&sb, report, EXPORT_CSV); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want_header := "id,inspections,items_held,throws_to_0,throws_to_1,throws_to_2,throws_to_3"
+	if got := strings.Join(records[0], ","); got != want_header {
+		t.Errorf("got header %s, want %s", got, want_header)
+	}
+
+	// the walkthrough's counts, with monkeys 0 and 1 holding every item at the end
+	want := [][]string{{"0", "101", "5"}, {"1", "95", "5"}, {"2", "7", "0"}, {"3", "105", "0"}}
+	if len(records) != len(want)+1 {
+		t.Fatalf("got %d records, want %d", len(records)-1, len(want))
+	}
+	for i, record := range records[1:] {
+		if got := strings.Join(record[:3], ","); got != strings.Join(want[i], ",") {
+			t.Errorf("monkey %d: got %s, want %s", i, got, strings.Join(want[i], ","))
+		}
+
+		// every inspection ends in a throw
+		var throws uint64
+		for _, field := range record[3:] {
+			count, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			throws += count
+		}
+		if strconv.FormatUint(throws, 10) != record[1] {
+			t.Errorf("monkey %d: got %d throws, want one for each of %s inspections", i, throws, record[1])
+		}
+	}
+}
+
+func TestWriteReportJSON(t *testing.T) {
+	report := exampleReport(t)
+
+	var sb strings.Builder
+	if err := WriteReport(&sb, report, EXPORT_JSON); err != nil {
+		t.Fatal(err)
+	}
+
+	// one object per monkey, which reads back as its stats
+	got := []MonkeyStats{}
+	d := json.NewDecoder(strings.NewReader(sb.String()))
+	for d.More() {
+		var s MonkeyStats
+		if err := d.Decode(&s); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+
+	if !reflect.DeepEqual(got, report.Monkeys) {
+		t.Errorf("got %+v, want %+v", got, report.Monkeys)
+	}
+
+	if err := WriteReport(&sb, report, "xml"); err == nil {
+		t.Error("got no error for an unknown format")
+	}
+}
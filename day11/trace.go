@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ThrowEvent is one item being inspected by a monkey and thrown to another
+// Worry levels are as kept by the simulation: reduced by the divisor product unless in the big worry mode
+type ThrowEvent struct {
+	Round       uint `json:"round"`
+	Item        int  `json:"item"`
+	From        int  `json:"from"`
+	To          int  `json:"to"`
+	WorryBefore any  `json:"worry_before"`
+	WorryAfter  any  `json:"worry_after"`
+}
+
+// TraceWriter writes ThrowEvents in one of the export formats
+type TraceWriter struct {
+	csv_w  *csv.Writer
+	json_e *json.Encoder
+}
+
+func NewTraceWriter(w io.Writer, format string) (*TraceWriter, error) {
+	tw := new(TraceWriter)
+
+	if err := checkExportFormat(format); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case EXPORT_CSV:
+		tw.csv_w = csv.NewWriter(w)
+		if err := tw.csv_w.Write([]string{"round", "item", "from", "to", "worry_before", "worry_after"}); err != nil {
+			return nil, err
+		}
+	case EXPORT_JSON:
+		tw.json_e = json.NewEncoder(w)
+	}
+
+	return tw, nil
+}
+
+func (tw *TraceWriter) Write(event ThrowEvent) error {
+	if tw.json_e != nil {
+		// *big.Int marshals as a JSON number, like uint64
+		return tw.json_e.Encode(event)
+	}
+
+	return tw.csv_w.Write([]string{
+		strconv.FormatUint(uint64(event.Round), 10),
+		strconv.Itoa(event.Item),
+		strconv.Itoa(event.From),
+		strconv.Itoa(event.To),
+		fmt.Sprint(event.WorryBefore),
+		fmt.Sprint(event.WorryAfter),
+	})
+}
+
+// Flush writes out anything buffered; it must be called once every event is written
+func (tw *TraceWriter) Flush() error {
+	if tw.csv_w != nil {
+		tw.csv_w.Flush()
+		return tw.csv_w.Error()
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// traceRun simulates the example for one round, as in the puzzle's walkthrough, writing every throw in the given format
+func traceRun(t *testing.T, format string, opts SimulationOptions) (string, Report) {
+	t.Helper()
+
+	var sb strings.Builder
+	tw, err := NewTraceWriter(&sb, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts.Trace = tw.Write
+	report, err := MonkeyBusiness(loadMonkeys(t, "example_input.txt"), 1, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	return sb.String(), report
+}
+
+func TestTraceCSV(t *testing.T) {
+	text, report := traceRun(t, EXPORT_CSV, SimulationOptions{Relief: DivideRelief{N: 3}})
+
+	records, err := csv.NewReader(strings.NewReader(text)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the walkthrough's first throws: monkey 0's items, then monkey 1's first
+	want := [][]string{
+		{"round", "item", "from", "to", "worry_before", "worry_after"},
+		{"1", "0", "0", "3", "79", "500"},
+		{"1", "1", "0", "3", "98", "620"},
+		{"1", "2", "1", "0", "54", "20"},
+	}
+	for i, want_record := range want {
+		if got := strings.Join(records[i], ","); got != strings.Join(want_record, ",") {
+			t.Errorf("record %d: got %s, want %s", i, got, strings.Join(want_record, ","))
+		}
+	}
+
+	// every inspection is traced once
+	var inspections uint64
+	for _, s := range report.Monkeys {
+		inspections += s.Inspections
+	}
+	if uint64(len(records)-1) != inspections {
+		t.Errorf("got %d throws traced, want one for each of %d inspections", len(records)-1, inspections)
+	}
+}
+
+func TestTraceJSON(t *testing.T) {
+	csv_text, _ := traceRun(t, EXPORT_CSV, SimulationOptions{Relief: DivideRelief{N: 3}})
+	records, err := csv.NewReader(strings.NewReader(csv_text)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the JSON trace holds the same throws, with worry levels as numbers whether or not they are big
+	for _, big_worry := range []bool{false, true} {
+		text, _ := traceRun(t, EXPORT_JSON, SimulationOptions{Relief: DivideRelief{N: 3}, BigWorry: big_worry})
+		lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+		if len(lines) != len(records)-1 {
+			t.Fatalf("big worry %v: got %d JSON throws, want %d", big_worry, len(lines), len(records)-1)
+		}
+
+		for i, line := range lines {
+			var event struct {
+				Round       uint        `json:"round"`
+				Item        int         `json:"item"`
+				From        int         `json:"from"`
+				To          int         `json:"to"`
+				WorryBefore json.Number `json:"worry_before"`
+				WorryAfter  json.Number `json:"worry_after"`
+			}
+			if err := json.Unmarshal([]byte(line), &event); err != nil {
+				t.Fatalf("big worry %v, line %d: %v", big_worry, i+1, err)
+			}
+
+			got := fmt.Sprintf("%d,%d,%d,%d,%s,%s", event.Round, event.Item, event.From, event.To, event.WorryBefore, event.WorryAfter)
+			if want := strings.Join(records[i+1], ","); got != want {
+				t.Errorf("big worry %v, line %d: got %s, want %s as in the CSV trace", big_worry, i+1, got, want)
+			}
+		}
+	}
+}
+
+func TestTraceFormatErrors(t *testing.T) {
+	for _, format := range []string{"", "xml", "CSV"} {
+		if _, err := NewTraceWriter(&strings.Builder{}, format); err == nil {
+			t.Errorf("'%s': got no error", format)
+		}
+	}
+}
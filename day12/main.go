@@ -2,7 +2,7 @@
 Package main solves Day 12 of Advent of Code 2022
 main.go: Laura Galbraith
 What is the fewest steps required to move from your current position to the location that should get the best signal?
-Compile and run: rm main.out; go clean; FMT_NEEDED=$(gofmt -e -d main.go | wc -l); if [ $FMT_NEEDED = 0 ]; then go build -o main.out main && ./main.out; else gofmt -e -d main.go; fi
+Compile and run: rm main.out; go clean; FMT_NEEDED=$(gofmt -e -d main.go | wc -l); if [ $FMT_NEEDED = 0 ]; then go build -o main.out day12 && ./main.out; else gofmt -e -d main.go; fi
 Go 1.19 used
 */
 package main
@@ -12,24 +12,25 @@ import (
 	"fmt"
 
 	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+	grid "github.com/lauragalbraith/AdventOfCode2022/util/gogrid"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
 )
 
 type CellToVisit struct {
 	row              int
 	col              int
 	dist_from_source uint
+	estimate         uint // of the steps left to a target, for A*; 0 otherwise
 }
 
-func (c *CellToVisit) GetNeighbors() []CellToVisit {
-	// directions: up,down,left,right
-	row_diffs := []int{0, 1, 0, -1}
-	col_diffs := []int{1, 0, -1, 0}
-	neighbors := make([]CellToVisit, len(row_diffs))
+// GetNeighbors returns the cells reached from c by each offset; some may be outside the heightmap
+func (c *CellToVisit) GetNeighbors(offsets []grid.Point) []CellToVisit {
+	neighbors := make([]CellToVisit, len(offsets))
 
-	for i, _ := range row_diffs {
+	for i, offset := range offsets {
 		neighbors[i] = CellToVisit{
-			row: c.row + row_diffs[i],
-			col: c.col + col_diffs[i],
+			row: c.row + offset.Row,
+			col: c.col + offset.Col,
 		}
 	}
 
@@ -47,7 +48,7 @@ func (pq *CellPriorityQueue) Len() int {
 // needed for sort.Interface
 func (pq *CellPriorityQueue) Less(i, j int) bool {
 	// return true if i has higher priority than j
-	return pq.cells[i].dist_from_source < pq.cells[j].dist_from_source
+	return pq.cells[i].dist_from_source+pq.cells[i].estimate < pq.cells[j].dist_from_source+pq.cells[j].estimate
 }
 
 // needed for sort.Interface
@@ -66,20 +67,13 @@ func (pq *CellPriorityQueue) Pop() any {
 	return cell_to_return
 }
 
-func FewestStepsFromSource(heightmap_lines []string, source_row, source_col int, ascending bool) [][]uint {
-	ROWS := len(heightmap_lines)
-	COLS := len(heightmap_lines[0])
-
-	// Track the minimum distance to source found
-	path_len := make([][]uint, ROWS)
-	for row, _ := range path_len {
-		path_len[row] = make([]uint, COLS)
-
-		for col, _ := range path_len[row] {
-			path_len[row][col] = uint(ROWS*COLS + 1)
-		}
-	}
-	path_len[source_row][source_col] = 0
+// FewestStepsFromSource finds the least cost under the rule from the source to every cell, and the cell each is best reached from
+// Under PUZZLE_RULE, the cost is the fewest steps
+func FewestStepsFromSource(heightmap *grid.Grid[rune], source_row, source_col int, ascending bool, rule MoveRule) ShortestPaths {
+	// Track the minimum distance to source found, and which cell each cell's best path arrives from
+	sp := newShortestPaths(heightmap, grid.Point{Row: source_row, Col: source_col}, ascending)
+	path_len := sp.Lengths
+	offsets := rule.offsets(ascending)
 
 	// Form a priority queue of cells to try next
 	var pq CellPriorityQueue
@@ -94,31 +88,19 @@ func FewestStepsFromSource(heightmap_lines []string, source_row, source_col int,
 		curr_cell.dist_from_source = path_len[curr_cell.row][curr_cell.col]
 
 		// add closer neighbors to the list to be considered
-		curr_height := int(heightmap_lines[curr_cell.row][curr_cell.col])
-
-		for _, n := range curr_cell.GetNeighbors() {
-			// check valid are bounds
-			if n.row < 0 || n.row >= ROWS || n.col < 0 || n.col >= COLS {
-				continue
-			}
-
+		for _, n := range curr_cell.GetNeighbors(offsets) {
 			// check visiting this neighbor is possible
-			n_height := int(heightmap_lines[n.row][n.col])
-			ascent := n_height - curr_height
-			if !ascending {
-				ascent = curr_height - n_height
-			}
-
-			// valid edges: destination cell can be at most one higher than elevation of current cell, and can be as low as you want
-			if ascent > 1 {
+			cost, allowed := rule.stepCost(heightmap, grid.Point{Row: curr_cell.row, Col: curr_cell.col}, grid.Point{Row: n.row, Col: n.col}, ascending)
+			if !allowed {
 				continue
 			}
 
 			// check if visiting from the current cell is an improvement
-			n.dist_from_source = curr_cell.dist_from_source + 1
+			n.dist_from_source = curr_cell.dist_from_source + cost
 			if path_len[n.row][n.col] > n.dist_from_source {
 				// update paths
 				path_len[n.row][n.col] = n.dist_from_source
+				sp.Predecessors.Set(grid.Point{Row: n.row, Col: n.col}, grid.Point{Row: curr_cell.row, Col: curr_cell.col})
 
 				// add neighbor to PQ
 				// do not bother removing old value in PQ; it should not amount to anything
@@ -127,58 +109,163 @@ func FewestStepsFromSource(heightmap_lines []string, source_row, source_col int,
 		}
 	}
 
-	return path_len
+	return sp
 }
 
-func main() {
-	// Get input
-	heightmap_lines, err := fileutil.GetLinesFromFile("input.txt")
+// Heightmap is the parsed puzzle input: heights as 'a' through 'z', with the source and end cells' markers replaced by their heights
+type Heightmap struct {
+	heights *grid.Grid[rune]
+	s, e    grid.Point
+}
+
+func ParseHeightmap(input_name string, _ solver.Params) (Heightmap, error) {
+	heightmap, err := grid.FromFile(input_name)
 	if err != nil {
-		panic(err)
+		return Heightmap{}, err
 	}
 
-	// Part 1: What is the fewest steps required to move from your current position to the location that should get the best signal?
-
 	// Save, then overwrite source and end cells
-	var s_row, s_col, e_row, e_col int
-	for r, row := range heightmap_lines {
-		for c, height := range row {
-			// S (at height a) is current position, E (at height z) is best signal location
-			if height == 'S' {
-				s_row, s_col = r, c
-
-				byte_arr := []byte(heightmap_lines[s_row])
-				byte_arr[s_col] = byte('a')
-				heightmap_lines[s_row] = string(byte_arr)
-			}
-			if height == 'E' {
-				e_row, e_col = r, c
-
-				byte_arr := []byte(heightmap_lines[e_row])
-				byte_arr[e_col] = byte('z')
-				heightmap_lines[e_row] = string(byte_arr)
-			}
-		}
+	// S (at height a) is current position, E (at height z) is best signal location
+	s, s_found := heightmap.Find('S')
+	e, e_found := heightmap.Find('E')
+	if !s_found || !e_found {
+		return Heightmap{}, fmt.Errorf("%s: heightmap must contain both 'S' and 'E'", fileutil.InputDisplayName(input_name))
 	}
 
-	// Compute all path lengths, treating 'S' as source
-	path_lengths := FewestStepsFromSource(heightmap_lines, s_row, s_col, true)
+	heightmap.Set(s, 'a')
+	heightmap.Set(e, 'z')
 
-	// Answer is path length to 'E' cell
-	fmt.Printf("\nPart 1 answer: %d\n", path_lengths[e_row][e_col])
-
-	// Part 2: What is the fewest steps required to move starting from any square with elevation a to the location that should get the best signal?
-	path_lengths = FewestStepsFromSource(heightmap_lines, e_row, e_col, false)
+	return Heightmap{heights: heightmap, s: s, e: e}, nil
+}
 
-	// find the minimum among all 'a' cells
-	min_path_len := path_lengths[s_row][s_col]
-	for r, row := range heightmap_lines {
-		for c, height := range row {
-			if height == 'a' && path_lengths[r][c] < min_path_len {
-				min_path_len = path_lengths[r][c]
-			}
-		}
+// day describes how to solve Day 12 for the solver harness
+func day() solver.Day[Heightmap] {
+	return solver.Day[Heightmap]{
+		Number: 12,
+		Params: solver.Params{
+			// file to draw each part's path in, over the heightmap (e.g. path_part1.txt for path.txt); empty for none
+			"render": "",
+			// how to search the heightmap: dijkstra, bfs or astar
+			"search": SEARCH_DIJKSTRA,
+			// what guides an astar search to 'E': zero, manhattan, height or max
+			"heuristic": HEURISTIC_MAX,
+			// which cells a step may reach (see ParseNeighborhood)
+			"neighborhood": NEIGHBORHOOD_4,
+			// how far a step may climb and drop (-1 for no limit), and what it costs (see ParseCost)
+			"max_ascent":  "1",
+			"max_descent": "-1",
+			"cost":        COST_UNIT,
+		},
+		Parse: ParseHeightmap,
+		Parts: []solver.Part[Heightmap]{
+			// Part 1: What is the fewest steps required to move from your current position to the location that should get the best signal?
+			func(h Heightmap, params solver.Params) (any, error) {
+				search, err := params.String("search")
+				if err == nil {
+					err = checkSearch(search)
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				rule, err := moveRuleFromParams(params)
+				if err != nil {
+					return nil, err
+				}
+
+				// Compute path lengths, treating 'S' as source
+				var paths ShortestPaths
+				switch search {
+				case SEARCH_ASTAR:
+					heuristic, err := params.String("heuristic")
+					if err != nil {
+						return nil, err
+					}
+
+					if paths, err = FewestStepsToTarget(h.heights, h.s, h.e, true, rule, heuristic); err != nil {
+						return nil, err
+					}
+				case SEARCH_BFS:
+					if paths, err = FewestStepsBFS(h.heights, h.s, true, rule); err != nil {
+						return nil, err
+					}
+				default:
+					paths = FewestStepsFromSource(h.heights, h.s.Row, h.s.Col, true, rule)
+				}
+
+				// with no path, show how far 'S' gets instead
+				if !paths.Reached(h.e) {
+					return noPath(params, 1, h.heights, paths, h.s, h.e)
+				}
+
+				path, _ := paths.PathTo(h.e)
+				if err := writeRender(params, 1, RenderPath(h.heights, path)); err != nil {
+					return nil, err
+				}
+
+				// Answer is path length to 'E' cell
+				return paths.Lengths[h.e.Row][h.e.Col], nil
+			},
+
+			// Part 2: What is the fewest steps required to move starting from any square with elevation a to the location that should get the best signal?
+			func(h Heightmap, params solver.Params) (any, error) {
+				search, err := params.String("search")
+				if err == nil {
+					err = checkSearch(search)
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				rule, err := moveRuleFromParams(params)
+				if err != nil {
+					return nil, err
+				}
+
+				var paths ShortestPaths
+				best_start := h.s
+				switch search {
+				case SEARCH_ASTAR:
+					// the search stops at the nearest 'a' cell; if there is none, 'S' is left as the start, and is unreached
+					var found bool
+					if paths, best_start, found = FewestStepsToHeight(h.heights, h.e, false, rule, 'a'); !found {
+						best_start = h.s
+					}
+				case SEARCH_BFS:
+					if paths, err = FewestStepsBFS(h.heights, h.e, false, rule); err != nil {
+						return nil, err
+					}
+				default:
+					paths = FewestStepsFromSource(h.heights, h.e.Row, h.e.Col, false, rule)
+				}
+				path_lengths := paths.Lengths
+
+				// find the minimum among all 'a' cells
+				min_path_len := path_lengths[best_start.Row][best_start.Col]
+				for _, a := range h.heights.FindAll('a') {
+					if path_lengths[a.Row][a.Col] < min_path_len {
+						min_path_len = path_lengths[a.Row][a.Col]
+						best_start = a
+					}
+				}
+
+				// with no path, show the cells which can get to 'E' instead
+				if !paths.Reached(best_start) {
+					return noPath(params, 2, h.heights, paths, h.s, h.e)
+				}
+
+				// the search went down from 'E', so its path is walked backwards
+				path, _ := paths.PathTo(best_start)
+				if err := writeRender(params, 2, RenderPath(h.heights, Reversed(path))); err != nil {
+					return nil, err
+				}
+
+				return min_path_len, nil
+			},
+		},
 	}
+}
 
-	fmt.Printf("\nPart 2 answer: %d\n", min_path_len)
+func main() {
+	solver.Run(day())
 }
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	grid "github.com/lauragalbraith/AdventOfCode2022/util/gogrid"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
+)
+
+// GenerateHeightmap makes a random heightmap which rises from 'a' at 'S' in the top left to 'z' at 'E' in the bottom right
+// Each cell is up to one off the slope, so some steps are too steep and the way up winds around them; the same seed always gives the same heightmap
+func GenerateHeightmap(rows, cols int, seed int64) Heightmap {
+	r := rand.New(rand.NewSource(seed))
+	heights := grid.New(rows, cols, 'a')
+
+	// the slope climbs from 0 to 25 across the whole diagonal
+	diagonal := rows + cols - 2
+	if diagonal < 1 {
+		diagonal = 1
+	}
+
+	heights.Each(func(p grid.Point, _ rune) {
+		height := 25*(p.Row+p.Col)/diagonal + r.Intn(3) - 1
+		if height < 0 {
+			height = 0
+		} else if height > 25 {
+			height = 25
+		}
+
+		heights.Set(p, 'a'+rune(height))
+	})
+
+	s := grid.Point{Row: 0, Col: 0}
+	e := grid.Point{Row: rows - 1, Col: cols - 1}
+	heights.Set(s, 'a')
+	heights.Set(e, 'z')
+
+	return Heightmap{heights: heights, s: s, e: e}
+}
+
+// searchToE finds the way from 'S' to 'E' by the named search, and heuristic for A*
+func searchToE(h Heightmap, rule MoveRule, search, heuristic string) (ShortestPaths, error) {
+	switch search {
+	case SEARCH_BFS:
+		return FewestStepsBFS(h.heights, h.s, true, rule)
+	case SEARCH_ASTAR:
+		return FewestStepsToTarget(h.heights, h.s, h.e, true, rule, heuristic)
+	}
+
+	return FewestStepsFromSource(h.heights, h.s.Row, h.s.Col, true, rule), nil
+}
+
+func TestSearchesAgree(t *testing.T) {
+	slope, err := ParseCost("slope:3,1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := map[string]MoveRule{
+		"puzzle":   PUZZLE_RULE,
+		"slope":    {MaxAscent: 1, MaxDescent: NO_LIMIT, Cost: slope},
+		"8":        {Neighborhood: grid.NEIGHBORS_8, MaxAscent: 1, MaxDescent: 2},
+		"knight":   {Neighborhood: KNIGHT_MOVES, MaxAscent: 2, MaxDescent: NO_LIMIT},
+		"one-way":  {Neighborhood: []grid.Point{{Row: 0, Col: 1}, {Row: 1, Col: 0}, {Row: -1, Col: 1}}, MaxAscent: 1, MaxDescent: NO_LIMIT},
+		"no climb": {MaxAscent: 0, MaxDescent: NO_LIMIT},
+	}
+
+	for name, rule := range rules {
+		for seed := int64(1); seed <= 5; seed++ {
+			h := GenerateHeightmap(40, 30, seed)
+			want, err := searchToE(h, rule, SEARCH_DIJKSTRA, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, search := range []string{SEARCH_BFS, SEARCH_ASTAR} {
+				for _, heuristic := range []string{HEURISTIC_ZERO, HEURISTIC_MANHATTAN, HEURISTIC_HEIGHT, HEURISTIC_MAX} {
+					if search == SEARCH_BFS && (heuristic != HEURISTIC_ZERO || !rule.Unit()) {
+						continue
+					}
+
+					got, err := searchToE(h, rule, search, heuristic)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if got.Reached(h.e) != want.Reached(h.e) || got.Lengths[h.e.Row][h.e.Col] != want.Lengths[h.e.Row][h.e.Col] {
+						t.Errorf("%s rule, seed %d: %s %s found %d to 'E', but dijkstra found %d", name, seed, search, heuristic, got.Lengths[h.e.Row][h.e.Col], want.Lengths[h.e.Row][h.e.Col])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNoPath(t *testing.T) {
+	h := GenerateHeightmap(10, 10, 1)
+
+	// a wall too high to climb, all the way across
+	for col := 0; col < 10; col++ {
+		h.heights.Set(grid.Point{Row: 5, Col: col}, 'z')
+	}
+	h.heights.Set(h.e, 'b')
+
+	paths := FewestStepsFromSource(h.heights, h.s.Row, h.s.Col, true, PUZZLE_RULE)
+	if paths.Reached(h.e) || paths.Lengths[h.e.Row][h.e.Col] != UNREACHABLE {
+		t.Fatalf("'E' should be unreachable, got a length of %d", paths.Lengths[h.e.Row][h.e.Col])
+	}
+	if _, found := paths.PathTo(h.e); found {
+		t.Error("PathTo found a path to 'E'")
+	}
+
+	region := RenderReachable(h.heights, paths, h.s, h.e)
+	if region[0] != 'S' {
+		t.Errorf("region should start with 'S', got:\n%s", region)
+	}
+	for _, c := range region[len(region)/2:] {
+		if c != '.' && c != '\n' {
+			t.Fatalf("nothing past the wall should be reached, got:\n%s", region)
+		}
+	}
+}
+
+func TestNoPathShowsRegion(t *testing.T) {
+	input_name := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(input_name, []byte("Sazz\nabzE\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// with no render file, the region is still shown after each answer, on stderr
+	for _, search := range []string{SEARCH_DIJKSTRA, SEARCH_BFS, SEARCH_ASTAR} {
+		var stdout, stderr strings.Builder
+		code := day().Main([]string{"-input", input_name, "-param", "search=" + search}, &stdout, &stderr)
+		if code != solver.EXIT_OK {
+			t.Fatalf("%s: exit code %d, stderr:\n%s", search, code, stderr.String())
+		}
+
+		want_stdout := "Part 1 answer: no path\nPart 2 answer: no path\n"
+		if stdout.String() != want_stdout {
+			t.Errorf("%s: got stdout\n%s\nwant\n%s", search, stdout.String(), want_stdout)
+		}
+
+		want_stderr := "Part 1: no path; the search reached only these cells:\nSa..\nab..\n" +
+			"Part 2: no path; the search reached only these cells:\n..zz\n..zE\n"
+		if stderr.String() != want_stderr {
+			t.Errorf("%s: got stderr\n%s\nwant\n%s", search, stderr.String(), want_stderr)
+		}
+	}
+}
+
+// benchmarkSearch times the named search from 'S' to 'E' on generated heightmaps of a few sizes, under the puzzle's rule
+func benchmarkSearch(b *testing.B, search, heuristic string) {
+	for _, size := range []int{100, 500, 1000} {
+		h := GenerateHeightmap(size, size, 1)
+
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := searchToE(h, PUZZLE_RULE, search, heuristic); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDijkstra(b *testing.B)       { benchmarkSearch(b, SEARCH_DIJKSTRA, "") }
+func BenchmarkBFS(b *testing.B)            { benchmarkSearch(b, SEARCH_BFS, "") }
+func BenchmarkAStarZero(b *testing.B)      { benchmarkSearch(b, SEARCH_ASTAR, HEURISTIC_ZERO) }
+func BenchmarkAStarManhattan(b *testing.B) { benchmarkSearch(b, SEARCH_ASTAR, HEURISTIC_MANHATTAN) }
+func BenchmarkAStarHeight(b *testing.B)    { benchmarkSearch(b, SEARCH_ASTAR, HEURISTIC_HEIGHT) }
+func BenchmarkAStarMax(b *testing.B)       { benchmarkSearch(b, SEARCH_ASTAR, HEURISTIC_MAX) }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	grid "github.com/lauragalbraith/AdventOfCode2022/util/gogrid"
+)
+
+// KNIGHT_MOVES are the offsets a chess knight jumps by, clockwise starting from up and to the right
+var KNIGHT_MOVES = []grid.Point{
+	{Row: -2, Col: 1}, {Row: -1, Col: 2}, {Row: 1, Col: 2}, {Row: 2, Col: 1},
+	{Row: 2, Col: -1}, {Row: 1, Col: -2}, {Row: -1, Col: -2}, {Row: -2, Col: -1},
+}
+
+// Names of neighborhoods, for ParseNeighborhood
+const (
+	NEIGHBORHOOD_4      = "4"      // up, down, left and right, as in the puzzle
+	NEIGHBORHOOD_8      = "8"      // diagonals too
+	NEIGHBORHOOD_KNIGHT = "knight" // KNIGHT_MOVES
+	NEIGHBORHOOD_CUSTOM = "custom" // "custom:R,C;R,C;...": any offsets, as rows then columns
+)
+
+// ParseNeighborhood reads the offsets a step may move by, e.g. "8" or "custom:-1,0;0,1"
+func ParseNeighborhood(text string) ([]grid.Point, error) {
+	name, args, _ := strings.Cut(text, ":")
+	switch name {
+	case NEIGHBORHOOD_4:
+		return grid.NEIGHBORS_4, nil
+	case NEIGHBORHOOD_8:
+		return grid.NEIGHBORS_8, nil
+	case NEIGHBORHOOD_KNIGHT:
+		return KNIGHT_MOVES, nil
+
+	case NEIGHBORHOOD_CUSTOM:
+		offsets := []grid.Point{}
+		seen := make(map[grid.Point]bool)
+		for _, pair := range strings.Split(args, ";") {
+			row_str, col_str, found := strings.Cut(pair, ",")
+			if !found {
+				return nil, fmt.Errorf("expected R,C offset in %s neighborhood, got '%s'", NEIGHBORHOOD_CUSTOM, pair)
+			}
+
+			row, err := strconv.Atoi(strings.TrimSpace(row_str))
+			if err != nil {
+				return nil, fmt.Errorf("row of %s neighborhood offset '%s': %w", NEIGHBORHOOD_CUSTOM, pair, err)
+			}
+			col, err := strconv.Atoi(strings.TrimSpace(col_str))
+			if err != nil {
+				return nil, fmt.Errorf("column of %s neighborhood offset '%s': %w", NEIGHBORHOOD_CUSTOM, pair, err)
+			}
+
+			offset := grid.Point{Row: row, Col: col}
+			if offset == (grid.Point{}) {
+				return nil, fmt.Errorf("%s neighborhood offsets must move, got '%s'", NEIGHBORHOOD_CUSTOM, pair)
+			}
+			if seen[offset] {
+				return nil, fmt.Errorf("%s neighborhood offsets must be different, got '%s' twice", NEIGHBORHOOD_CUSTOM, pair)
+			}
+			seen[offset] = true
+			offsets = append(offsets, offset)
+		}
+
+		return offsets, nil
+	}
+
+	return nil, fmt.Errorf("unknown neighborhood '%s' (expected %s, %s, %s or %s:R,C;R,C;...)", text, NEIGHBORHOOD_4, NEIGHBORHOOD_8, NEIGHBORHOOD_KNIGHT, NEIGHBORHOOD_CUSTOM)
+}
+
+// offsetReach is the furthest any one offset moves across rows, across columns, and in rows and columns together
+func offsetReach(offsets []grid.Point) (rows, cols, total int) {
+	for _, o := range offsets {
+		r, c := o.Row, o.Col
+		if r < 0 {
+			r = -r
+		}
+		if c < 0 {
+			c = -c
+		}
+
+		if r > rows {
+			rows = r
+		}
+		if c > cols {
+			cols = c
+		}
+		if r+c > total {
+			total = r + c
+		}
+	}
+
+	return rows, cols, total
+}
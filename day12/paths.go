@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	grid "github.com/lauragalbraith/AdventOfCode2022/util/gogrid"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
+)
+
+// NO_PREDECESSOR marks cells with no cell before them on a path: the source, and cells which cannot be reached
+var NO_PREDECESSOR = grid.Point{Row: -1, Col: -1}
+
+// UNREACHABLE is the length of the path to a cell the search found no path to
+const UNREACHABLE = uint(math.MaxUint)
+
+// ShortestPaths are the results of searching the heightmap from a source cell
+type ShortestPaths struct {
+	Source    grid.Point
+	Ascending bool
+	// Lengths are the least cost (under the puzzle's rule, the fewest steps) from the source to each cell, by row then column, or UNREACHABLE
+	Lengths [][]uint
+	// Predecessors are the cell each cell is reached from on one of its shortest paths
+	Predecessors *grid.Grid[grid.Point]
+}
+
+// Reached is true if the search found a path to p
+func (sp ShortestPaths) Reached(p grid.Point) bool {
+	if !sp.Predecessors.InBounds(p) {
+		return false
+	}
+
+	return sp.Lengths[p.Row][p.Col] != UNREACHABLE
+}
+
+// PathTo returns the cells of a shortest path from the source to target, including both, or false if target cannot be reached
+// For a descending search, this path runs down the hill from the source; see Reversed
+func (sp ShortestPaths) PathTo(target grid.Point) ([]grid.Point, bool) {
+	if !sp.Predecessors.InBounds(target) {
+		return nil, false
+	}
+
+	// walk back from target to source, then turn it around
+	path := []grid.Point{target}
+	for curr := target; curr != sp.Source; {
+		prev, _ := sp.Predecessors.Get(curr)
+		if prev == NO_PREDECESSOR {
+			return nil, false
+		}
+
+		path = append(path, prev)
+		curr = prev
+	}
+
+	return Reversed(path), true
+}
+
+// Reversed returns a copy of path in the opposite order
+func Reversed(path []grid.Point) []grid.Point {
+	reversed := make([]grid.Point, len(path))
+	for i, p := range path {
+		reversed[len(path)-1-i] = p
+	}
+
+	return reversed
+}
+
+// ARROWS show which way a path leaves a cell, as in the puzzle's illustration
+var ARROWS = map[grid.Point]rune{
+	{Row: -1, Col: 0}: '^',
+	{Row: 0, Col: 1}:  '>',
+	{Row: 1, Col: 0}:  'v',
+	{Row: 0, Col: -1}: '<',
+}
+
+// RenderPath draws the path over the heightmap: each cell of the path shows an arrow to the next, the last shows 'E', and every other cell is '.'
+// Steps other than the 4 arrow directions are drawn as '*'
+func RenderPath(heightmap *grid.Grid[rune], path []grid.Point) string {
+	marks := make(map[grid.Point]rune, len(path))
+	for i, p := range path {
+		if i == len(path)-1 {
+			marks[p] = 'E'
+			continue
+		}
+
+		arrow, found := ARROWS[path[i+1].Sub(p)]
+		if !found {
+			arrow = '*'
+		}
+		marks[p] = arrow
+	}
+
+	return heightmap.Render(func(p grid.Point, _ rune) rune {
+		if mark, found := marks[p]; found {
+			return mark
+		}
+		return '.'
+	})
+}
+
+// RenderReachable draws the region the search reached: the height of each cell reached ('S' and 'E' for the ends of the walk), and '.' for every other cell
+// For a descending search, the region is the cells which can reach its source
+func RenderReachable(heightmap *grid.Grid[rune], sp ShortestPaths, s, e grid.Point) string {
+	return heightmap.Render(func(p grid.Point, height rune) rune {
+		switch {
+		case !sp.Reached(p):
+			return '.'
+		case p == s:
+			return 'S'
+		case p == e:
+			return 'E'
+		}
+		return height
+	})
+}
+
+// NO_PATH is a part's answer when no start it may use can reach 'E'
+const NO_PATH = "no path"
+
+// NoPath is the answer of a part which found no path, explained by the region its search reached (see RenderReachable)
+type NoPath struct {
+	Region string
+}
+
+func (NoPath) String() string { return NO_PATH }
+
+func (n NoPath) Explanation() string {
+	return fmt.Sprintf("%s; the search reached only these cells:\n%s", NO_PATH, n.Region)
+}
+
+// noPath answers a part which found no path with the region its search reached, also drawing it in place of a path
+func noPath(params solver.Params, part int, heightmap *grid.Grid[rune], sp ShortestPaths, s, e grid.Point) (any, error) {
+	region := RenderReachable(heightmap, sp, s, e)
+	if err := writeRender(params, part, region); err != nil {
+		return nil, err
+	}
+
+	return NoPath{Region: region}, nil
+}
+
+// writeRender draws the part's path, or region, to the file named by the "render" parameter, if there is one
+// Each part gets its own file, e.g. path_part1.txt for path.txt
+func writeRender(params solver.Params, part int, drawing string) error {
+	name, err := params.String("render")
+	if err != nil || len(name) == 0 {
+		return err
+	}
+
+	ext := filepath.Ext(name)
+	return os.WriteFile(fmt.Sprintf("%s_part%d%s", strings.TrimSuffix(name, ext), part, ext), []byte(drawing), 0644)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+
+	grid "github.com/lauragalbraith/AdventOfCode2022/util/gogrid"
+)
+
+// checkPath fails the test unless path climbs from start to 'E' in answer steps, each allowed by the puzzle's rule
+func checkPath(t *testing.T, h Heightmap, path []grid.Point, start_height rune, answer uint) {
+	t.Helper()
+
+	if len(path) != int(answer)+1 {
+		t.Fatalf("got a path of %d cells, want %d for %d steps", len(path), answer+1, answer)
+	}
+
+	if start, _ := h.heights.Get(path[0]); start != start_height {
+		t.Errorf("path starts at %v, with height %c rather than %c", path[0], start, start_height)
+	}
+	if path[len(path)-1] != h.e {
+		t.Errorf("path ends at %v rather than 'E' at %v", path[len(path)-1], h.e)
+	}
+
+	for i := 1; i < len(path); i++ {
+		if _, allowed := PUZZLE_RULE.stepCost(h.heights, path[i-1], path[i], true); !allowed {
+			t.Errorf("step %d from %v to %v is not allowed", i, path[i-1], path[i])
+		}
+	}
+}
+
+func TestPathTo(t *testing.T) {
+	h, err := ParseHeightmap("example_input.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// part 1 climbs from 'S'
+	up := FewestStepsFromSource(h.heights, h.s.Row, h.s.Col, true, PUZZLE_RULE)
+	path, found := up.PathTo(h.e)
+	if !found {
+		t.Fatal("no path from 'S' to 'E'")
+	}
+	if path[0] != h.s {
+		t.Errorf("part 1 path starts at %v rather than 'S' at %v", path[0], h.s)
+	}
+	checkPath(t, h, path, 'a', 31)
+
+	// the puzzle draws another of the shortest paths, which starts by going down
+	want_render := "" +
+		">v.v<<<<\n" +
+		".>vvv<<^\n" +
+		"..vv>E^^\n" +
+		"..v>>>^^\n" +
+		"..>>>>>^\n"
+	if got := RenderPath(h.heights, path); got != want_render {
+		t.Errorf("got part 1 render\n%s\nwant\n%s", got, want_render)
+	}
+
+	// part 2 searches down from 'E', so its path is reversed to climb
+	down := FewestStepsFromSource(h.heights, h.e.Row, h.e.Col, false, PUZZLE_RULE)
+	start := grid.Point{Row: 4, Col: 0}
+	path, found = down.PathTo(start)
+	if !found {
+		t.Fatalf("no path from 'E' down to %v", start)
+	}
+	if path[0] != h.e {
+		t.Errorf("part 2 path starts at %v rather than 'E' at %v", path[0], h.e)
+	}
+	checkPath(t, h, Reversed(path), 'a', 29)
+
+	want_render = "" +
+		"...v<<<<\n" +
+		"...vv<<^\n" +
+		"...v>E^^\n" +
+		".>v>>>^^\n" +
+		">^>>>>>^\n"
+	if got := RenderPath(h.heights, Reversed(path)); got != want_render {
+		t.Errorf("got part 2 render\n%s\nwant\n%s", got, want_render)
+	}
+
+	// cells with no path, and cells off the map, have no path to them
+	flat := MoveRule{MaxAscent: 1, MaxDescent: 0}
+	unreached := FewestStepsFromSource(h.heights, h.e.Row, h.e.Col, true, flat)
+	for _, p := range []grid.Point{h.s, {Row: -1, Col: 0}, {Row: 0, Col: h.heights.Cols()}} {
+		if path, found := unreached.PathTo(p); found {
+			t.Errorf("got path %v from 'E' to %v without dropping, want none", path, p)
+		}
+	}
+}
+
+func TestRenderPathSteps(t *testing.T) {
+	heights := grid.New(3, 3, 'a')
+
+	// a knight's move and a diagonal are drawn as '*', as there are no arrows for them
+	path := []grid.Point{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 2, Col: 2}, {Row: 1, Col: 1}, {Row: 1, Col: 0}}
+	want := "" +
+		">*.\n" +
+		"E<.\n" +
+		"..*\n"
+	if got := RenderPath(heights, path); got != want {
+		t.Errorf("got render\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	grid "github.com/lauragalbraith/AdventOfCode2022/util/gogrid"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
+)
+
+// NO_LIMIT lets a MoveRule step up or down any distance
+const NO_LIMIT = -1
+
+// MoveRule decides which steps between neighboring cells are allowed, and what each costs
+type MoveRule struct {
+	// Neighborhood is the offsets a step may move by (see ParseNeighborhood); nil is the puzzle's grid.NEIGHBORS_4
+	Neighborhood []grid.Point
+	MaxAscent    int // highest a step may climb, or NO_LIMIT
+	MaxDescent   int // furthest a step may drop, or NO_LIMIT
+	// Cost is the effort of a step changing height by delta (negative going down); nil makes every step cost 1
+	Cost func(delta int) uint
+}
+
+// PUZZLE_RULE is the puzzle's: climb at most one, drop as far as you like, and count steps
+var PUZZLE_RULE = MoveRule{MaxAscent: 1, MaxDescent: NO_LIMIT}
+
+// Unit is true if every step costs the same 1, so the fewest steps is also the least effort
+func (rule MoveRule) Unit() bool {
+	return rule.Cost == nil
+}
+
+// offsets are those a search steps by; a descending search works back from the end of the walk, so takes each step in reverse
+func (rule MoveRule) offsets(ascending bool) []grid.Point {
+	offsets := rule.Neighborhood
+	if offsets == nil {
+		offsets = grid.NEIGHBORS_4
+	}
+	if ascending {
+		return offsets
+	}
+
+	reversed := make([]grid.Point, len(offsets))
+	for i, o := range offsets {
+		reversed[i] = grid.Point{Row: -o.Row, Col: -o.Col}
+	}
+	return reversed
+}
+
+// allows is true if a step changing height by delta is allowed
+func (rule MoveRule) allows(delta int) bool {
+	if delta > 0 && rule.MaxAscent != NO_LIMIT {
+		return delta <= rule.MaxAscent
+	}
+	if delta < 0 && rule.MaxDescent != NO_LIMIT {
+		return -delta <= rule.MaxDescent
+	}
+	return true
+}
+
+// stepCost is the cost of stepping from one cell to a neighbor, or false if the step is not allowed or leaves the heightmap
+// A descending search works back from the end of the walk, so its step from -> to is the walk's step to -> from
+func (rule MoveRule) stepCost(heightmap *grid.Grid[rune], from, to grid.Point, ascending bool) (uint, bool) {
+	from_height, from_ok := heightmap.Get(from)
+	to_height, to_ok := heightmap.Get(to)
+	if !from_ok || !to_ok {
+		return 0, false
+	}
+
+	delta := int(to_height) - int(from_height)
+	if !ascending {
+		delta = -delta
+	}
+
+	if !rule.allows(delta) {
+		return 0, false
+	}
+	if rule.Unit() {
+		return 1, true
+	}
+	return rule.Cost(delta), true
+}
+
+// minStepCost is the least any allowed step between heights 'a' and 'z' can cost, for heuristics to scale by
+func (rule MoveRule) minStepCost() uint {
+	if rule.Unit() {
+		return 1
+	}
+
+	min_cost := uint(math.MaxUint)
+	for delta := -25; delta <= 25; delta++ {
+		if cost := rule.Cost(delta); rule.allows(delta) && cost < min_cost {
+			min_cost = cost
+		}
+	}
+
+	return min_cost
+}
+
+// Names of cost functions, for ParseCost
+const (
+	COST_UNIT  = "unit"  // every step costs 1
+	COST_SLOPE = "slope" // "slope:U,D": a step costs 1, plus U for each level climbed and D for each level dropped
+)
+
+// ParseCost reads a cost function for a MoveRule, e.g. "unit" or "slope:3,1"; unit cost is nil
+func ParseCost(text string) (func(delta int) uint, error) {
+	name, args, _ := strings.Cut(text, ":")
+	switch name {
+	case COST_UNIT:
+		return nil, nil
+
+	case COST_SLOPE:
+		up_str, down_str, found := strings.Cut(args, ",")
+		if !found {
+			return nil, fmt.Errorf("%s cost needs the cost of climbing and of dropping, e.g. \"%s:3,1\"", COST_SLOPE, COST_SLOPE)
+		}
+
+		up, err := strconv.ParseUint(strings.TrimSpace(up_str), 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s cost of climbing: %w", COST_SLOPE, err)
+		}
+		down, err := strconv.ParseUint(strings.TrimSpace(down_str), 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s cost of dropping: %w", COST_SLOPE, err)
+		}
+
+		return func(delta int) uint {
+			if delta > 0 {
+				return 1 + uint(up)*uint(delta)
+			}
+			return 1 + uint(down)*uint(-delta)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown cost '%s' (expected %s or %s:U,D)", text, COST_UNIT, COST_SLOPE)
+}
+
+// moveRuleFromParams builds the rule from the "neighborhood", "max_ascent", "max_descent" and "cost" parameters
+func moveRuleFromParams(params solver.Params) (MoveRule, error) {
+	neighborhood_text, err := params.String("neighborhood")
+	if err != nil {
+		return MoveRule{}, err
+	}
+	neighborhood, err := ParseNeighborhood(neighborhood_text)
+	if err != nil {
+		return MoveRule{}, err
+	}
+
+	max_ascent, err := params.Int("max_ascent")
+	if err != nil {
+		return MoveRule{}, err
+	}
+	max_descent, err := params.Int("max_descent")
+	if err != nil {
+		return MoveRule{}, err
+	}
+	if max_ascent < NO_LIMIT || max_descent < NO_LIMIT {
+		return MoveRule{}, fmt.Errorf("max_ascent and max_descent must be at least 0, or %d for no limit", NO_LIMIT)
+	}
+
+	cost_text, err := params.String("cost")
+	if err != nil {
+		return MoveRule{}, err
+	}
+	cost, err := ParseCost(cost_text)
+	if err != nil {
+		return MoveRule{}, err
+	}
+
+	return MoveRule{Neighborhood: neighborhood, MaxAscent: max_ascent, MaxDescent: max_descent, Cost: cost}, nil
+}
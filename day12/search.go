@@ -0,0 +1,225 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+
+	grid "github.com/lauragalbraith/AdventOfCode2022/util/gogrid"
+)
+
+// Names of search algorithms, for the "search" parameter
+const (
+	SEARCH_DIJKSTRA = "dijkstra" // FewestStepsFromSource: every cell, by priority queue
+	SEARCH_BFS      = "bfs"      // FewestStepsBFS: every cell, by first-in first-out queue; only for unit costs
+	SEARCH_ASTAR    = "astar"    // FewestStepsToTarget and FewestStepsToHeight: only as far as the answer
+)
+
+func checkSearch(search string) error {
+	switch search {
+	case SEARCH_DIJKSTRA, SEARCH_BFS, SEARCH_ASTAR:
+		return nil
+	}
+
+	return fmt.Errorf("unknown search '%s' (expected %s, %s or %s)", search, SEARCH_DIJKSTRA, SEARCH_BFS, SEARCH_ASTAR)
+}
+
+// newShortestPaths starts a search from source, with no cell reached but the source
+func newShortestPaths(heightmap *grid.Grid[rune], source grid.Point, ascending bool) ShortestPaths {
+	ROWS := heightmap.Rows()
+	COLS := heightmap.Cols()
+
+	path_len := make([][]uint, ROWS)
+	for row := range path_len {
+		path_len[row] = make([]uint, COLS)
+
+		for col := range path_len[row] {
+			path_len[row][col] = UNREACHABLE
+		}
+	}
+	path_len[source.Row][source.Col] = 0
+
+	return ShortestPaths{Source: source, Ascending: ascending, Lengths: path_len, Predecessors: grid.New(ROWS, COLS, NO_PREDECESSOR)}
+}
+
+// FewestStepsBFS finds the same as FewestStepsFromSource, but as every step counts the same, a first-in first-out queue is enough to visit cells in order of distance
+// It only follows the rule's limits on climbing and dropping; its cost must be unit
+func FewestStepsBFS(heightmap *grid.Grid[rune], source grid.Point, ascending bool, rule MoveRule) (ShortestPaths, error) {
+	if !rule.Unit() {
+		return ShortestPaths{}, fmt.Errorf("%s search needs every step to cost the same; use %s or %s for other costs", SEARCH_BFS, SEARCH_DIJKSTRA, SEARCH_ASTAR)
+	}
+
+	sp := newShortestPaths(heightmap, source, ascending)
+	offsets := rule.offsets(ascending)
+	visited := grid.New(heightmap.Rows(), heightmap.Cols(), false)
+	visited.Set(source, true)
+
+	queue := []grid.Point{source}
+	for head := 0; head < len(queue); head++ {
+		curr := queue[head]
+
+		for _, n := range heightmap.Neighbors(curr, offsets) {
+			if seen, _ := visited.Get(n); seen {
+				continue
+			}
+			if _, allowed := rule.stepCost(heightmap, curr, n, ascending); !allowed {
+				continue
+			}
+
+			// the first visit is by a shortest path
+			visited.Set(n, true)
+			sp.Lengths[n.Row][n.Col] = sp.Lengths[curr.Row][curr.Col] + 1
+			sp.Predecessors.Set(n, curr)
+			queue = append(queue, n)
+		}
+	}
+
+	return sp, nil
+}
+
+// Heuristic estimates the steps left from p to target under the rule without ever overestimating
+// A* scales it by the cheapest step the rule allows, so it still finds a cheapest path
+type Heuristic func(heightmap *grid.Grid[rune], rule MoveRule, p, target grid.Point, ascending bool) uint
+
+// Names of heuristics, as listed in HEURISTICS
+const (
+	HEURISTIC_ZERO      = "zero"      // A* becomes Dijkstra, stopping at the target
+	HEURISTIC_MANHATTAN = "manhattan" // every step moves at most as far as the furthest offset in the rule's neighborhood
+	HEURISTIC_HEIGHT    = "height"    // every step climbs at most the rule's maximum ascent
+	HEURISTIC_MAX       = "max"       // the better of manhattan and height
+)
+
+var HEURISTICS = map[string]Heuristic{
+	HEURISTIC_ZERO:      func(_ *grid.Grid[rune], _ MoveRule, _, _ grid.Point, _ bool) uint { return 0 },
+	HEURISTIC_MANHATTAN: ManhattanHeuristic,
+	HEURISTIC_HEIGHT:    HeightHeuristic,
+	HEURISTIC_MAX: func(heightmap *grid.Grid[rune], rule MoveRule, p, target grid.Point, ascending bool) uint {
+		manhattan := ManhattanHeuristic(heightmap, rule, p, target, ascending)
+		height := HeightHeuristic(heightmap, rule, p, target, ascending)
+		if height > manhattan {
+			return height
+		}
+		return manhattan
+	},
+}
+
+// ManhattanHeuristic is the Manhattan distance to target for 4-way steps; other neighborhoods can cover more of it, or more rows or columns, in a step
+func ManhattanHeuristic(_ *grid.Grid[rune], rule MoveRule, p, target grid.Point, _ bool) uint {
+	d := target.Sub(p)
+	if d.Row < 0 {
+		d.Row = -d.Row
+	}
+	if d.Col < 0 {
+		d.Col = -d.Col
+	}
+
+	// the furthest steps go is the same both ways
+	reach_rows, reach_cols, reach_total := offsetReach(rule.offsets(true))
+
+	steps := 0
+	for _, bound := range [][2]int{{d.Row + d.Col, reach_total}, {d.Row, reach_rows}, {d.Col, reach_cols}} {
+		// a neighborhood which cannot move that way never gets there; leaving it out still underestimates
+		if bound[1] > 0 && (bound[0]+bound[1]-1)/bound[1] > steps {
+			steps = (bound[0] + bound[1] - 1) / bound[1]
+		}
+	}
+
+	return uint(steps)
+}
+
+func HeightHeuristic(heightmap *grid.Grid[rune], rule MoveRule, p, target grid.Point, ascending bool) uint {
+	p_height, _ := heightmap.Get(p)
+	target_height, _ := heightmap.Get(target)
+
+	return heightToClimb(rule, p_height, target_height, ascending)
+}
+
+// heightToClimb is the fewest steps from a cell of height from to one of height to, as each climbs at most the rule's maximum ascent
+func heightToClimb(rule MoveRule, from, to rune, ascending bool) uint {
+	climb := int(to) - int(from)
+	if !ascending {
+		climb = -climb
+	}
+
+	if climb <= 0 || rule.MaxAscent == NO_LIMIT {
+		return 0
+	}
+
+	// a rule which cannot climb at all never gets there; 1 step is still an underestimate
+	max_ascent := rule.MaxAscent
+	if max_ascent < 1 {
+		max_ascent = 1
+	}
+	return uint((climb + max_ascent - 1) / max_ascent)
+}
+
+// AStar searches from source until it reaches a cell is_target accepts, visiting cells in order of their cost so far plus estimate
+// estimate must never overestimate the cost to the nearest target, and must not drop by more than the cost of any step; then the target found is a cheapest one
+// Cells the search did not need to settle are left UNREACHABLE, or with only an upper bound on their cost; if no target is found, every cell reachable is settled
+func AStar(heightmap *grid.Grid[rune], source grid.Point, ascending bool, rule MoveRule, is_target func(p grid.Point) bool, estimate func(p grid.Point) uint) (ShortestPaths, grid.Point, bool) {
+	sp := newShortestPaths(heightmap, source, ascending)
+	path_len := sp.Lengths
+	offsets := rule.offsets(ascending)
+
+	var pq CellPriorityQueue
+	heap.Init(&pq)
+	heap.Push(&pq, CellToVisit{row: source.Row, col: source.Col, dist_from_source: 0, estimate: estimate(source)})
+
+	for len(pq.cells) > 0 {
+		curr_cell := heap.Pop(&pq).(CellToVisit)
+		curr := grid.Point{Row: curr_cell.row, Col: curr_cell.col}
+
+		// skip cells since reached by a shorter path
+		if curr_cell.dist_from_source > path_len[curr.Row][curr.Col] {
+			continue
+		}
+
+		if is_target(curr) {
+			return sp, curr, true
+		}
+
+		for _, n := range heightmap.Neighbors(curr, offsets) {
+			cost, allowed := rule.stepCost(heightmap, curr, n, ascending)
+			if !allowed {
+				continue
+			}
+
+			dist := curr_cell.dist_from_source + cost
+			if path_len[n.Row][n.Col] > dist {
+				path_len[n.Row][n.Col] = dist
+				sp.Predecessors.Set(n, curr)
+				heap.Push(&pq, CellToVisit{row: n.Row, col: n.Col, dist_from_source: dist, estimate: estimate(n)})
+			}
+		}
+	}
+
+	return sp, NO_PREDECESSOR, false
+}
+
+// FewestStepsToTarget finds the cheapest way from source to target with A*, guided by the named heuristic
+func FewestStepsToTarget(heightmap *grid.Grid[rune], source, target grid.Point, ascending bool, rule MoveRule, heuristic string) (ShortestPaths, error) {
+	h, found := HEURISTICS[heuristic]
+	if !found {
+		return ShortestPaths{}, fmt.Errorf("unknown heuristic '%s' (expected %s, %s, %s or %s)", heuristic, HEURISTIC_ZERO, HEURISTIC_MANHATTAN, HEURISTIC_HEIGHT, HEURISTIC_MAX)
+	}
+
+	min_cost := rule.minStepCost()
+	sp, _, _ := AStar(heightmap, source, ascending, rule,
+		func(p grid.Point) bool { return p == target },
+		func(p grid.Point) uint { return h(heightmap, rule, p, target, ascending) * min_cost })
+
+	return sp, nil
+}
+
+// FewestStepsToHeight finds the cheapest cell of the given height to reach from source with A*, guided by how far there is to climb to that height
+func FewestStepsToHeight(heightmap *grid.Grid[rune], source grid.Point, ascending bool, rule MoveRule, height rune) (ShortestPaths, grid.Point, bool) {
+	min_cost := rule.minStepCost()
+	return AStar(heightmap, source, ascending, rule,
+		func(p grid.Point) bool {
+			p_height, _ := heightmap.Get(p)
+			return p_height == height
+		},
+		func(p grid.Point) uint {
+			p_height, _ := heightmap.Get(p)
+			return heightToClimb(rule, p_height, height, ascending) * min_cost
+		})
+}
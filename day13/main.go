@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
 )
 
 type Value interface {
@@ -125,7 +126,8 @@ func ParseListFromPacket(packet string, is_divider bool) (*ListValue, error) {
 
 			inner_list, err := ParseListFromPacket(packet[start_bracket:end_bracket+1], false) // inner lists of divider are not, themselves, a divider
 			if err != nil {
-				return list, err
+				// shift the inner list's column so it is relative to the start of this packet
+				return list, fileutil.ShiftColumn(err, start_bracket)
 			}
 
 			curr = inner_list
@@ -136,8 +138,7 @@ func ParseListFromPacket(packet string, is_divider bool) (*ListValue, error) {
 			}
 			val, err := strconv.Atoi(packet[int_start:i])
 			if err != nil {
-				fmt.Printf("Failed to parse int from '%s': %+v\n", packet[int_start:i], err)
-				return list, err
+				return list, fileutil.Errorf(0, int_start+1, "failed to parse int from '%s': %w", packet[int_start:i], err)
 			}
 
 			int_val := new(IntegerValue)
@@ -232,66 +233,87 @@ func (p Packets) Swap(i, j int) {
 }
 func (p Packets) Less(i, j int) bool { return Compare(p[i], p[j]) != Incorrect }
 
-func main() {
-	// Get input
-	received_packets, err := fileutil.GetLinesFromFile("input.txt")
+// ParsePackets reads the packets of the named input in order; packets come in pairs, separated by blank lines
+func ParsePackets(input_name string, _ solver.Params) ([]Value, error) {
+	packet_pairs, err := fileutil.GetBlocksFromFile(input_name)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	// Parse packets into values
 	var values []Value
-	for _, packet := range received_packets {
-		// skip blank lines
-		if len(packet) <= 0 {
-			continue
+	for _, pair := range packet_pairs {
+		if len(pair.Lines) != 2 {
+			err := fileutil.Errorf(pair.FirstLine, 0, "expected a pair of packets, found %d: %w", len(pair.Lines), fileutil.ErrUnexpectedFormat)
+			return nil, fileutil.WithFile(err, fileutil.InputDisplayName(input_name))
 		}
 
-		list_value, err := ParseListFromPacket(packet, false)
-		if err != nil {
-			panic(err)
-		}
+		for i, packet := range pair.Lines {
+			list_value, err := ParseListFromPacket(packet, false)
+			if err != nil {
+				return nil, fileutil.WithFile(fileutil.AtLine(err, pair.FirstLine+i), fileutil.InputDisplayName(input_name))
+			}
 
-		values = append(values, list_value)
+			values = append(values, list_value)
+		}
 	}
 
-	// Compare pairs of packets
-	correct_order_sum := 0
-	for pair_index := 1; pair_index <= len(values)/2; pair_index++ {
-		left_value_index := (pair_index - 1) * 2
-		right_value_index := (pair_index-1)*2 + 1
+	return values, nil
+}
 
-		if Compare(values[left_value_index], values[right_value_index]) == Correct {
-			correct_order_sum += pair_index
-		}
-	}
+func main() {
+	solver.Run(solver.Day[[]Value]{
+		Number: 13,
+		Parse:  ParsePackets,
+		Parts: []solver.Part[[]Value]{
+			// Part 1
+			func(values []Value, _ solver.Params) (any, error) {
+				// Compare pairs of packets
+				correct_order_sum := 0
+				for pair_index := 1; pair_index <= len(values)/2; pair_index++ {
+					left_value_index := (pair_index - 1) * 2
+					right_value_index := (pair_index-1)*2 + 1
+
+					if Compare(values[left_value_index], values[right_value_index]) == Correct {
+						correct_order_sum += pair_index
+					}
+				}
 
-	fmt.Printf("\nPart 1 answer: %+v\n", correct_order_sum)
+				return correct_order_sum, nil
+			},
 
-	// Part 2
-	// add divider packets into list
-	divider2, err := ParseListFromPacket("[[2]]", true)
-	if err != nil {
-		panic(err)
-	}
-	values = append(values, divider2)
+			// Part 2
+			func(received []Value, _ solver.Params) (any, error) {
+				// copy the list so sorting it does not reorder the received packets
+				values := make([]Value, len(received), len(received)+2)
+				copy(values, received)
 
-	divider6, err := ParseListFromPacket("[[6]]", true)
-	if err != nil {
-		panic(err)
-	}
-	values = append(values, divider6)
+				// add divider packets into list
+				divider2, err := ParseListFromPacket("[[2]]", true)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, divider2)
 
-	// sort all of the packets into the correct order, including the dividers
-	sort.Sort(Packets(values))
+				divider6, err := ParseListFromPacket("[[6]]", true)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, divider6)
 
-	// answer is the indeces of the divider packets multiplied together
-	decoder_key := 1
-	for i, packet := range values {
-		if packet.IsDivider() {
-			decoder_key *= i + 1 // 1-indexed number from 0-indexed list
-		}
-	}
+				// sort all of the packets into the correct order, including the dividers
+				sort.Sort(Packets(values))
+
+				// answer is the indeces of the divider packets multiplied together
+				decoder_key := 1
+				for i, packet := range values {
+					if packet.IsDivider() {
+						decoder_key *= i + 1 // 1-indexed number from 0-indexed list
+					}
+				}
 
-	fmt.Printf("\nPart 2 answer: %v\n", decoder_key)
+				return decoder_key, nil
+			},
+		},
+	})
 }
@@ -13,6 +13,8 @@ import (
 	"strconv"
 
 	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+	grid "github.com/lauragalbraith/AdventOfCode2022/util/gogrid"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
 )
 
 const (
@@ -33,15 +35,9 @@ const (
 	Sand
 )
 
-func PrintGrid(grid [][]int) {
+func PrintGrid(cave *grid.Grid[int]) {
 	fmt.Println("DEBUG: this is the grid:")
-	for _, row := range grid {
-		for _, tile := range row {
-			fmt.Printf("%c", (".#o")[tile])
-		}
-		fmt.Println()
-	}
-	fmt.Println()
+	fmt.Println(cave.Render(func(_ grid.Point, tile int) rune { return rune((".#o")[tile]) }))
 }
 
 // GetRockLineCoordinatesFromInput returns a list of rock lines, which are a list of turning points, which are a pair of row,col values
@@ -60,18 +56,18 @@ func GetRockLineCoordinatesFromInput(file_name string) ([][][]int, error) {
 		ret[line_i] = make([][]int, len(coordinates_found))
 		for coord_i, coord := range coordinates_found {
 			if len(coord) < 3 {
-				return nil, fmt.Errorf("Unexpected input line format: '%s'", line)
+				return nil, fileutil.WithFile(fileutil.Errorf(line_i+1, 0, "'%s': %w", line, fileutil.ErrUnexpectedFormat), file_name)
 			}
 
 			// NOTE: x = col (distance right), y = row (distance down)
 			x, err := strconv.Atoi(coord[1])
 			if err != nil {
-				return nil, err
+				return nil, fileutil.WithFile(fileutil.AtLine(err, line_i+1), file_name)
 			}
 
 			y, err := strconv.Atoi(coord[2])
 			if err != nil {
-				return nil, err
+				return nil, fileutil.WithFile(fileutil.AtLine(err, line_i+1), file_name)
 			}
 
 			ret[line_i][coord_i] = make([]int, 2)
@@ -84,7 +80,7 @@ func GetRockLineCoordinatesFromInput(file_name string) ([][][]int, error) {
 }
 
 // FillInRocks populates spaces that have rock lines, as specified by cave input
-func FillInRocks(grid [][]int, rock_lines [][][]int) {
+func FillInRocks(cave *grid.Grid[int], rock_lines [][][]int) {
 	for _, rock_line := range rock_lines {
 		var rock_line_start_row, rock_line_start_col int
 
@@ -109,7 +105,7 @@ func FillInRocks(grid [][]int, rock_lines [][][]int) {
 				// draw the line, whether vertical or horizontal
 				for row := row_start; row <= row_end; row++ {
 					for col := col_start; col <= col_end; col++ {
-						grid[row][col] = Rock
+						cave.Set(grid.Point{Row: row, Col: col}, Rock)
 					}
 				}
 			}
@@ -122,21 +118,27 @@ func FillInRocks(grid [][]int, rock_lines [][][]int) {
 }
 
 // Emulate sand: flows one unit (cell/tile) at a time, comes to rest, and then the next sand is produced
-func EmulateSand(grid [][]int) int {
+func EmulateSand(cave *grid.Grid[int]) int {
+	// cells outside the cave are never air; the cave is sized so that sand should never reach them
+	is_air := func(row, col int) bool {
+		tile, in_bounds := cave.Get(grid.Point{Row: row, Col: col})
+		return in_bounds && tile == Air
+	}
+
 	still_sand := 0
 
 	// Let sand fall until the sand source is blocked
-	for ; grid[SAND_SOURCE_ROW][SAND_SOURCE_COL] == Air; still_sand++ {
+	for ; is_air(SAND_SOURCE_ROW, SAND_SOURCE_COL); still_sand++ {
 		sand_row := SAND_SOURCE_ROW
 		sand_col := SAND_SOURCE_COL
 		for sand_row < FLOOR_ROW {
 			// Try to fall down, then diagonally 1 down & 1 left, then diagonally 1 down & 1 right
-			if grid[sand_row+1][sand_col] == Air {
+			if is_air(sand_row+1, sand_col) {
 				sand_row++
-			} else if grid[sand_row+1][sand_col-1] == Air {
+			} else if is_air(sand_row+1, sand_col-1) {
 				sand_row++
 				sand_col--
-			} else if grid[sand_row+1][sand_col+1] == Air {
+			} else if is_air(sand_row+1, sand_col+1) {
 				sand_row++
 				sand_col++
 			} else {
@@ -151,16 +153,17 @@ func EmulateSand(grid [][]int) int {
 		}
 
 		// Note where sand landed still
-		grid[sand_row][sand_col] = Sand
+		cave.Set(grid.Point{Row: sand_row, Col: sand_col}, Sand)
 	}
 
 	return still_sand
 }
 
-func main() {
-	rock_line_coordinates, err := GetRockLineCoordinatesFromInput("input.txt")
+// ParseCave reads the rock lines of the named input and draws them into an empty cave, which is sized for both parts
+func ParseCave(input_name string, _ solver.Params) (*grid.Grid[int], error) {
+	rock_line_coordinates, err := GetRockLineCoordinatesFromInput(input_name)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	// Determine size of grid
@@ -182,36 +185,36 @@ func main() {
 	FLOOR_ROW = 2 + MAX_ROW
 
 	// NOTE: both inputs do not have any lines that jut up near the left or top of the cave (0), so we shouldn't have to worry about emulating negative indeces
-	grid := make([][]int, FLOOR_ROW+1) // floor is edge
-	for row := range grid {
-		// if we can pile up on the floor, then make sure that COLS also stretches out to MAX_COL+MAX_ROW (give a couple more) so that sand can pile up in a diagonal line to the sand source
-		grid[row] = make([]int, MAX_COL+4+FLOOR_ROW)
-		// each tile is set to int's default value: 0, which is Air
-	}
-
-	// since grid is a slice (created by "make"), it is effectively passed by reference
-	FillInRocks(grid, rock_line_coordinates)
+	// floor is edge
+	// if we can pile up on the floor, then make sure that COLS also stretches out to MAX_COL+MAX_ROW (give a couple more) so that sand can pile up in a diagonal line to the sand source
+	cave := grid.New(FLOOR_ROW+1, MAX_COL+4+FLOOR_ROW, Air)
 
-	// since grid is a slice (created by "make"), it is effectively passed by reference
-	still_sand := EmulateSand(grid)
+	// since cave is a pointer, it is updated in place
+	FillInRocks(cave, rock_line_coordinates)
 
-	// How many units of sand come to rest before sand starts flowing into the abyss below?
-	fmt.Printf("Part 1 answer: %v\n", still_sand)
-
-	// Part 2
-	// draw infinite floor
-	FillInRocks(grid, [][][]int{{{FLOOR_ROW, 0}, {FLOOR_ROW, len(grid[0]) - 1}}})
-
-	// clear grid of sand
-	for r, row := range grid {
-		for c, tile := range row {
-			if tile == Sand {
-				grid[r][c] = Air
-			}
-		}
-	}
+	return cave, nil
+}
 
-	// re-emulate to get correct number of sand units
-	still_sand = EmulateSand(grid)
-	fmt.Printf("Part 2 answer: %v\n", still_sand)
+func main() {
+	solver.Run(solver.Day[*grid.Grid[int]]{
+		Number: 14,
+		Parse:  ParseCave,
+		Parts: []solver.Part[*grid.Grid[int]]{
+			// How many units of sand come to rest before sand starts flowing into the abyss below?
+			func(rocky_cave *grid.Grid[int], _ solver.Params) (any, error) {
+				// since cave is a pointer, it is updated in place, so work on a copy
+				return EmulateSand(rocky_cave.DeepCopy()), nil
+			},
+
+			// Part 2
+			func(rocky_cave *grid.Grid[int], _ solver.Params) (any, error) {
+				cave := rocky_cave.DeepCopy()
+
+				// draw infinite floor
+				FillInRocks(cave, [][][]int{{{FLOOR_ROW, 0}, {FLOOR_ROW, cave.Cols() - 1}}})
+
+				return EmulateSand(cave), nil
+			},
+		},
+	})
 }
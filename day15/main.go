@@ -8,26 +8,17 @@ Go 1.19 used
 package main
 
 import (
+	"context"
 	"fmt"
-	"regexp"
 	"sort"
-	"strconv"
 
 	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
 )
 
 var (
-	// Problem parameters
-	params = map[string][]int64{
-		"input.txt":         {2000000, 4000000},
-		"example_input.txt": {10, 20},
-	}
-
-	DESIRED_Y int64
-	X_Y_LIMIT int64
-
-	// Regex for parsing the problem input
-	input_re = regexp.MustCompile(`^Sensor at x=(\-{0,1}\d+), y=(\-{0,1}\d+): closest beacon is at x=(\-{0,1}\d+), y=(\-{0,1}\d+)$`)
+	// Decoder for parsing the problem input
+	input_decoder = fileutil.MustNewDecoder[SensorInput](`^Sensor at x=(?P<x>\-{0,1}\d+), y=(?P<y>\-{0,1}\d+): closest beacon is at x=(?P<beacon_x>\-{0,1}\d+), y=(?P<beacon_y>\-{0,1}\d+)$`)
 )
 
 const (
@@ -74,35 +65,22 @@ func (s *Sensor) String() string {
 	return fmt.Sprintf("sensor:(%d,%d) with closest beacon:(%d,%d) at distance %d", s.x, s.y, s.beacon_x, s.beacon_y, s.manhattan_to_closest_beacon)
 }
 
-func ParseInputToSensor(input string) (*Sensor, error) {
-	s := new(Sensor)
-
-	matches := input_re.FindAllStringSubmatch(input, -1)
-	if len(matches) < 1 || len(matches[0]) < 5 {
-		return nil, fmt.Errorf("unexpected input format: '%s'", input)
-	}
+// SensorInput holds the values from a single line of the problem input
+type SensorInput struct {
+	X       int64 `re:"x"`
+	Y       int64 `re:"y"`
+	BeaconX int64 `re:"beacon_x"`
+	BeaconY int64 `re:"beacon_y"`
+}
 
+func ParseInputToSensor(input string) (*Sensor, error) {
 	// extract x,y coordinates of the sensor and its closest beacon
-	var err error
-	s.x, err = strconv.ParseInt(matches[0][1], 10, 64)
+	in, err := input_decoder.Decode(input)
 	if err != nil {
 		return nil, err
 	}
 
-	s.y, err = strconv.ParseInt(matches[0][2], 10, 64)
-	if err != nil {
-		return nil, err
-	}
-
-	s.beacon_x, err = strconv.ParseInt(matches[0][3], 10, 64)
-	if err != nil {
-		return nil, err
-	}
-
-	s.beacon_y, err = strconv.ParseInt(matches[0][4], 10, 64)
-	if err != nil {
-		return nil, err
-	}
+	s := &Sensor{x: in.X, y: in.Y, beacon_x: in.BeaconX, beacon_y: in.BeaconY}
 
 	// calculate manhattan distance
 	manhattan_x := s.x - s.beacon_x
@@ -120,32 +98,14 @@ func ParseInputToSensor(input string) (*Sensor, error) {
 	return s, nil
 }
 
-func main() {
-	// Choose parameters for run
-	input_file_name := "input.txt"
-	DESIRED_Y = params[input_file_name][0]
-	X_Y_LIMIT = params[input_file_name][1]
-
-	// Parse input as sensor data
-	input_lines, err := fileutil.GetLinesFromFile(input_file_name)
-	if err != nil {
-		panic(err)
-	}
-
-	sensors := make([]*Sensor, len(input_lines))
-	for i, line := range input_lines {
-		sensors[i], err = ParseInputToSensor(line)
-		if err != nil {
-			panic(err)
-		}
-	}
-
+// CountImpossibleBeaconPositions returns how many positions on row desired_y cannot contain a beacon
+func CountImpossibleBeaconPositions(sensors []*Sensor, desired_y int64) int {
 	// Part 1: counting the positions where a beacon cannot possibly be along just a single row
 	beacon_impossible_on_desired_y := make(map[int64]bool)
 
 	// Calculate the range of the sensor's impossible-area
 	for _, s := range sensors {
-		lesser_x, greater_x := s.CoveredRange(DESIRED_Y)
+		lesser_x, greater_x := s.CoveredRange(desired_y)
 
 		// if lesser_x > greater_x, the equation is not satisfied and this block does nothing
 		for x := lesser_x; x <= greater_x; x++ {
@@ -156,21 +116,24 @@ func main() {
 	// Remove the actual beacons from the result
 	// (do not remove actual sensors, because it is true that a cell containing a sensor cannot contain a beacon)
 	for _, s := range sensors {
-		if s.beacon_y != DESIRED_Y {
+		if s.beacon_y != desired_y {
 			continue
 		}
 
 		delete(beacon_impossible_on_desired_y, s.beacon_x)
 	}
 
-	fmt.Printf("\nPart 1 answer: %v\n", len(beacon_impossible_on_desired_y))
+	return len(beacon_impossible_on_desired_y)
+}
 
+// TuningFrequency returns the tuning frequency of the only position within x_y_limit that could contain the distress beacon
+func TuningFrequency(sensors []*Sensor, x_y_limit int64) int64 {
 	// Part 2: Find the only possible position for the distress beacon
 	// For each y value, go over all ranges covered by the sensors on that row to find any gaps of 1
 
 	var y, x int64
 Y_Loop:
-	for y = 0; y <= X_Y_LIMIT; y++ {
+	for y = 0; y <= x_y_limit; y++ {
 		// collect all covered ranges of this y value
 		var covered []Range
 		for _, s := range sensors {
@@ -208,11 +171,63 @@ Y_Loop:
 		}
 
 		// try to find a gap at the right edge
-		if max_covered_x < X_Y_LIMIT {
-			x = X_Y_LIMIT
+		if max_covered_x < x_y_limit {
+			x = x_y_limit
 			break Y_Loop
 		}
 	}
 
-	fmt.Printf("\nPart 2 answer: %v\n", x*TUNING_FREQUENCY_X_MULTIPLIER+y)
+	return x*TUNING_FREQUENCY_X_MULTIPLIER + y
+}
+
+// ParseSensors reads the sensor data of the named input, one line at a time
+func ParseSensors(input_name string, _ solver.Params) ([]*Sensor, error) {
+	var sensors []*Sensor
+	err := fileutil.ScanLinesFromFile(context.Background(), input_name, func(line fileutil.Line) error {
+		s, err := ParseInputToSensor(line.Text)
+		if err != nil {
+			return err
+		}
+
+		sensors = append(sensors, s)
+		return nil
+	})
+
+	return sensors, err
+}
+
+func main() {
+	solver.Run(solver.Day[[]*Sensor]{
+		Number: 15,
+
+		// Problem parameters: the row to count for part 1, and the limit of x and y values to search for part 2
+		Params: solver.Params{
+			"desired_y": "2000000",
+			"x_y_limit": "4000000",
+		},
+		InputParams: map[string]solver.Params{
+			"example_input.txt": {"desired_y": "10", "x_y_limit": "20"},
+		},
+
+		Parse: ParseSensors,
+		Parts: []solver.Part[[]*Sensor]{
+			func(sensors []*Sensor, params solver.Params) (any, error) {
+				desired_y, err := params.Int64("desired_y")
+				if err != nil {
+					return nil, err
+				}
+
+				return CountImpossibleBeaconPositions(sensors, desired_y), nil
+			},
+
+			func(sensors []*Sensor, params solver.Params) (any, error) {
+				x_y_limit, err := params.Int64("x_y_limit")
+				if err != nil {
+					return nil, err
+				}
+
+				return TuningFrequency(sensors, x_y_limit), nil
+			},
+		},
+	})
 }
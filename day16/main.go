@@ -9,14 +9,21 @@ package main
 
 import (
 	"container/heap"
+	"context"
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 
 	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
 )
 
+// ValveInput holds the values from a single line of the problem input
+type ValveInput struct {
+	Name     string   `re:"name"`
+	FlowRate int      `re:"flow_rate"`
+	Tunnels  []string `re:"tunnels"`
+}
+
 type Valve struct {
 	name      string
 	flow_rate int
@@ -28,27 +35,17 @@ func (v *Valve) String() string {
 }
 
 var (
-	input_re = regexp.MustCompile(`^Valve (.+) has flow rate=(\d+); tunnel[s]{0,1} lead[s]{0,1} to valve[s]{0,1} (.+)$`)
+	input_decoder = fileutil.MustNewDecoder[ValveInput](`^Valve (?P<name>.+) has flow rate=(?P<flow_rate>\d+); tunnel[s]{0,1} lead[s]{0,1} to valve[s]{0,1} (?P<tunnels>.+)$`)
 )
 
 func CreateValveForGraph(input string, graph map[string]*Valve) error {
-	info := input_re.FindAllStringSubmatch(input, -1)
-	if len(info) < 1 || len(info[0]) < 4 {
-		return fmt.Errorf("unexpected input format: '%s'", input)
-	}
-
-	v := new(Valve)
-
-	// file in info from input
-	v.name = info[0][1]
-
-	var err error
-	v.flow_rate, err = strconv.Atoi(info[0][2])
+	info, err := input_decoder.Decode(input)
 	if err != nil {
 		return err
 	}
 
-	v.tunnels = strings.Split(info[0][3], ", ")
+	// fill in info from input
+	v := &Valve{name: info.Name, flow_rate: info.FlowRate, tunnels: info.Tunnels}
 
 	// add to graph
 	graph[v.name] = v
@@ -286,23 +283,24 @@ func DFSValuableValveDistance(
 	return max_released
 }
 
-func main() {
-	// valve flow units: pressure per minute in open state
-	input_lines, err := fileutil.GetLinesFromFile("input.txt")
-	if err != nil {
-		panic(err)
-	}
+// Volcano is the parsed puzzle input: the original graph of valves, and the transformed graph between the valuable ones
+type Volcano struct {
+	valve_tunnel_graph            map[string]*Valve
+	valuable_valve_distance_graph map[string]map[string]int
+}
 
+func ParseVolcano(input_name string, _ solver.Params) (Volcano, error) {
+	// valve flow units: pressure per minute in open state
 	// NOTE no negative flow rates in either input
 	// NOTE: all flow rates are unique and <30 but they're not all primes, so we couldn't just factor the 30-minute value so far
 
 	// Store the original as a graph of valves as nodes and tunnels as edges
 	valve_tunnel_graph := make(map[string]*Valve)
-	for _, line := range input_lines {
-		err := CreateValveForGraph(line, valve_tunnel_graph)
-		if err != nil {
-			panic(err)
-		}
+	err := fileutil.ScanLinesFromFile(context.Background(), input_name, func(line fileutil.Line) error {
+		return CreateValveForGraph(line.Text, valve_tunnel_graph)
+	})
+	if err != nil {
+		return Volcano{}, err
 	}
 
 	// Transform the original graph into a graph of valves with nonzero flow rate (and AA) as nodes and shortest path between them as edges:
@@ -330,34 +328,44 @@ func main() {
 		}
 	}
 
-	// Perform a DFS on the transformed graph to find the path resulting in the maximum released pressure
-	visited := make(map[string]bool)
-	visited[START_VALVE] = true
-	max_released_pressure := DFSValuableValveDistance(
-		valve_tunnel_graph,
-		valuable_valve_distance_graph,
-		visited,
-		[]int{1},
-		[]string{START_VALVE},
-		0,
-		[]int{0})
+	return Volcano{valve_tunnel_graph: valve_tunnel_graph, valuable_valve_distance_graph: valuable_valve_distance_graph}, nil
+}
 
-	// What is the most pressure you could release in 30 minutes?
-	fmt.Printf("\nPart 1 answer: %v\n", max_released_pressure)
+// MaxReleasedPressure performs a DFS on the transformed graph to find the path resulting in the maximum released pressure, with one creature per starting minute
+func MaxReleasedPressure(v Volcano, start_minutes []int) int {
+	valves := make([]string, len(start_minutes))
+	open_flow_rates := make([]int, len(start_minutes))
+	for i := range valves {
+		valves[i] = START_VALVE
+	}
 
-	// Reset visited status for Part 2
-	visited = make(map[string]bool)
+	visited := make(map[string]bool)
 	visited[START_VALVE] = true
 
-	// Run DFS for both a human and elephant, starting later and both at AA
-	max_released_pressure = DFSValuableValveDistance(
-		valve_tunnel_graph,
-		valuable_valve_distance_graph,
+	return DFSValuableValveDistance(
+		v.valve_tunnel_graph,
+		v.valuable_valve_distance_graph,
 		visited,
-		[]int{5, 5},
-		[]string{START_VALVE, START_VALVE},
+		start_minutes,
+		valves,
 		0,
-		[]int{0, 0})
+		open_flow_rates)
+}
 
-	fmt.Printf("\nPart 2 answer: %v\n", max_released_pressure)
+func main() {
+	solver.Run(solver.Day[Volcano]{
+		Number: 16,
+		Parse:  ParseVolcano,
+		Parts: []solver.Part[Volcano]{
+			// What is the most pressure you could release in 30 minutes?
+			func(v Volcano, _ solver.Params) (any, error) {
+				return MaxReleasedPressure(v, []int{1}), nil
+			},
+
+			// Run DFS for both a human and elephant, starting later and both at AA
+			func(v Volcano, _ solver.Params) (any, error) {
+				return MaxReleasedPressure(v, []int{5, 5}), nil
+			},
+		},
+	})
 }
@@ -8,9 +8,11 @@ Go 1.19 used
 package main
 
 import (
-	"fmt"
+	"context"
 
 	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+	grid "github.com/lauragalbraith/AdventOfCode2022/util/gogrid"
+	solver "github.com/lauragalbraith/AdventOfCode2022/util/gosolver"
 )
 
 // Problem constants
@@ -77,11 +79,11 @@ func get_rock_tile_indeces(
 }
 
 // returns -1 if there are no rocks
-func get_tallest_rock_row(tiles [][]byte) int {
+func get_tallest_rock_row(tiles *grid.Grid[byte]) int {
 	// look at the topmost row, continuing down until we see a rock
-	for tiles_row := len(tiles) - 1; tiles_row >= 0; tiles_row-- {
+	for tiles_row := tiles.Rows() - 1; tiles_row >= 0; tiles_row-- {
 		for col := 0; col < COLS; col++ {
-			if tiles[tiles_row][col] == ROCK {
+			if tile, _ := tiles.Get(grid.Point{Row: tiles_row, Col: col}); tile == ROCK {
 				return tiles_row
 			}
 		}
@@ -91,7 +93,7 @@ func get_tallest_rock_row(tiles [][]byte) int {
 }
 
 // in Go, I'm not sure there's a way to do native hashing for a type (like size_t operator() in C++); could just create an INT out of hashing together the occupied tiles in the top R rows
-func hash_chamber_top(tiles [][]byte) uint64 {
+func hash_chamber_top(tiles *grid.Grid[byte]) uint64 {
 	var hash uint64 // Go initializes to 0
 
 	for i := 0; i < ROWS_TO_HASH; i++ {
@@ -101,7 +103,7 @@ func hash_chamber_top(tiles [][]byte) uint64 {
 		}
 		for col := 0; col < COLS; col++ {
 			var val uint64 // Go initializes to 0
-			if row < len(tiles) && tiles[row][col] == ROCK {
+			if tile, _ := tiles.Get(grid.Point{Row: row, Col: col}); tile == ROCK {
 				val = 1
 			}
 
@@ -123,13 +125,8 @@ func TallestTowerHeightAfterXFalls(
 	rock_type_i := 0
 
 	// Store all relevant tiles: a tower could have gauges in its side that perfectly fit a rock being pushed into it, but the tallest rock at all columns wouldn't be able to tell you that
-	tiles := make([][]byte, ROCK_START_ROW_BUFFER)
-	for row := range tiles {
-		tiles[row] = make([]byte, COLS)
-		for col := range tiles[row] {
-			tiles[row][col] = AIRE
-		}
-	}
+	// row 0 is the floor, so the grid grows upwards as rows are added
+	tiles := grid.New[byte](ROCK_START_ROW_BUFFER, COLS, AIRE)
 
 	// Store hashed state of the upper (most relevant) chamber to detect repeated states (stable cycle)
 	seen_jet_type_chamber_heights := make(map[int]map[int]map[uint64][]int64)
@@ -168,7 +165,7 @@ func TallestTowerHeightAfterXFalls(
 
 					// check if it is blocked by another rock
 					// if the row isn't even accounted for in the tiles, it cannot be a rock
-					if tile_row < len(tiles) && tiles[tile_row][new_tile_col] == ROCK {
+					if tile, _ := tiles.Get(grid.Point{Row: tile_row, Col: new_tile_col}); tile == ROCK {
 						push_impeded = true
 						break
 					}
@@ -200,7 +197,7 @@ func TallestTowerHeightAfterXFalls(
 
 					// check if it is blocked by another rock
 					// if the row isn't even accounted for in the tiles, it cannot be a rock
-					if new_tile_row < len(tiles) && tiles[new_tile_row][tile_col] == ROCK {
+					if tile, _ := tiles.Get(grid.Point{Row: new_tile_row, Col: tile_col}); tile == ROCK {
 						fall_impeded = true
 						break
 					}
@@ -215,11 +212,8 @@ func TallestTowerHeightAfterXFalls(
 			// if fall fails, note state and move onto next rock
 
 			// allocate new rows in tiles if needed
-			for int(rock_row)+len(ROCKS[rock_type_i])-1 >= len(tiles) {
-				tiles = append(tiles, make([]byte, COLS))
-				for col := 0; col < COLS; col++ {
-					tiles[len(tiles)-1][col] = AIRE
-				}
+			if rows_needed := rock_row + len(ROCKS[rock_type_i]) - tiles.Rows(); rows_needed > 0 {
+				tiles.AddRows(rows_needed, AIRE)
 			}
 
 			// store all of this rock's tiles in tiles
@@ -230,7 +224,7 @@ func TallestTowerHeightAfterXFalls(
 					}
 
 					tile_row, tile_col := get_rock_tile_indeces(rock_row, rock_col, rock_type_i, i, j)
-					tiles[tile_row][tile_col] = ROCK
+					tiles.Set(grid.Point{Row: tile_row, Col: tile_col}, ROCK)
 				}
 			}
 
@@ -289,18 +283,55 @@ func TallestTowerHeightAfterXFalls(
 	return int64(get_tallest_rock_row(tiles) + 1)
 }
 
-func main() {
-	// Parse input
-	input_lines, err := fileutil.GetLinesFromFile("input.txt")
+// ParseJetPattern reads the jet pattern, which is entirely on the first line of the named input, so it stops reading after it
+func ParseJetPattern(input_name string, _ solver.Params) (string, error) {
+	var jet_pattern string
+	err := fileutil.ScanLinesFromFile(context.Background(), input_name, func(line fileutil.Line) error {
+		jet_pattern = line.Text
+		return fileutil.ErrStopScan
+	})
 	if err != nil {
-		panic(err)
+		return "", err
+	}
+
+	if len(jet_pattern) == 0 {
+		return "", fileutil.WithFile(fileutil.Errorf(1, 0, "no jet pattern: %w", fileutil.ErrUnexpectedFormat), fileutil.InputDisplayName(input_name))
+	}
+
+	for i := 0; i < len(jet_pattern); i++ {
+		if _, valid := JET_DIRECTIONS[jet_pattern[i]]; !valid {
+			return "", fileutil.WithFile(fileutil.Errorf(1, i+1, "invalid jet '%c': %w", jet_pattern[i], fileutil.ErrUnexpectedFormat), fileutil.InputDisplayName(input_name))
+		}
 	}
 
-	// How many units tall will the tower of rocks be after 2022 rocks have stopped falling?
-	answer := TallestTowerHeightAfterXFalls(2022, input_lines[0])
-	fmt.Printf("\nPart 1 answer: %v\n", answer)
+	return jet_pattern, nil
+}
+
+// tallestTowerPart solves a part by simulating the number of rocks given by the named parameter
+func tallestTowerPart(rocks_param string) solver.Part[string] {
+	return func(jet_pattern string, params solver.Params) (any, error) {
+		rocks, err := params.Int64(rocks_param)
+		if err != nil {
+			return nil, err
+		}
 
-	// How tall will the tower be after 1000000000000 rocks have stopped?
-	answer = TallestTowerHeightAfterXFalls(1000000000000, input_lines[0])
-	fmt.Printf("\nPart 2 answer: %v\n", answer)
+		return TallestTowerHeightAfterXFalls(rocks, jet_pattern), nil
+	}
+}
+
+func main() {
+	solver.Run(solver.Day[string]{
+		Number: 17,
+		Params: solver.Params{
+			// How many units tall will the tower of rocks be after 2022 rocks have stopped falling?
+			"part1_rocks": "2022",
+			// How tall will the tower be after 1000000000000 rocks have stopped?
+			"part2_rocks": "1000000000000",
+		},
+		Parse: ParseJetPattern,
+		Parts: []solver.Part[string]{
+			tallestTowerPart("part1_rocks"),
+			tallestTowerPart("part2_rocks"),
+		},
+	})
 }
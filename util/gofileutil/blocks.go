@@ -0,0 +1,122 @@
+package fileutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Block is a group of consecutive non-blank lines, as separated by blank lines in the input
+type Block struct {
+	FirstLine int // line number of Lines[0], 1-indexed
+	Lines     []string
+}
+
+// BlockFunc is called once per block while scanning; returning ErrStopScan ends the scan early without error
+type BlockFunc func(block Block) error
+
+func isBlankLine(text string) bool {
+	return len(strings.TrimSpace(text)) == 0
+}
+
+// ScanBlocks reads r one block at a time; any number of blank lines (including whitespace-only ones) separate blocks, and leading or trailing blank lines are ignored
+func ScanBlocks(ctx context.Context, r io.Reader, fn BlockFunc) error {
+	var curr Block
+	stopped := false
+
+	err := ScanLines(ctx, r, func(line Line) error {
+		if !isBlankLine(line.Text) {
+			if len(curr.Lines) == 0 {
+				curr.FirstLine = line.Number
+			}
+			curr.Lines = append(curr.Lines, line.Text)
+			return nil
+		}
+
+		if len(curr.Lines) == 0 {
+			return nil
+		}
+
+		// a blank line ends the current block
+		block := curr
+		curr = Block{}
+
+		err := fn(block)
+		if errors.Is(err, ErrStopScan) {
+			stopped = true
+			return err
+		}
+		// errors are attributed to the start of the block rather than the blank line that ended it
+		return AtLine(err, block.FirstLine)
+	})
+	if err != nil || stopped {
+		return err
+	}
+
+	// the input may end without a blank line after the last block
+	if len(curr.Lines) > 0 {
+		err = fn(curr)
+		if errors.Is(err, ErrStopScan) {
+			return nil
+		}
+		return AtLine(err, curr.FirstLine)
+	}
+
+	return nil
+}
+
+// ScanBlocksFromFile streams the blocks of the named file ("-" for stdin) to fn one at a time
+func ScanBlocksFromFile(ctx context.Context, name string, fn BlockFunc) error {
+	r, err := OpenInput(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return WithFile(ScanBlocks(ctx, r, fn), InputDisplayName(name))
+}
+
+// GetBlocksFromFile returns every block of the named file ("-" for stdin)
+func GetBlocksFromFile(name string) ([]Block, error) {
+	blocks := []Block{}
+	err := ScanBlocksFromFile(context.Background(), name, func(block Block) error {
+		blocks = append(blocks, block)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// GroupBlocks splits lines which have already been read into blocks, following the same rules as ScanBlocks
+func GroupBlocks(lines []string) []Block {
+	blocks := []Block{}
+
+	var curr Block
+	for i, text := range lines {
+		text = strings.TrimSuffix(text, "\r")
+
+		if isBlankLine(text) {
+			if len(curr.Lines) > 0 {
+				blocks = append(blocks, curr)
+				curr = Block{}
+			}
+			continue
+		}
+
+		if len(curr.Lines) == 0 {
+			curr.FirstLine = i + 1
+		}
+		curr.Lines = append(curr.Lines, text)
+	}
+
+	if len(curr.Lines) > 0 {
+		blocks = append(blocks, curr)
+	}
+
+	return blocks
+}
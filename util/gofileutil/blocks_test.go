@@ -0,0 +1,93 @@
+package fileutil
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Block
+	}{
+		{name: "empty", input: "", want: []Block{}},
+		{name: "only blank lines", input: "\n  \n\t\n", want: []Block{}},
+		{
+			name:  "one block",
+			input: "a\nb\n",
+			want:  []Block{{FirstLine: 1, Lines: []string{"a", "b"}}},
+		},
+		{
+			name:  "leading and trailing blank lines",
+			input: "\n\na\nb\n\n\n",
+			want:  []Block{{FirstLine: 3, Lines: []string{"a", "b"}}},
+		},
+		{
+			name:  "several blank lines between blocks",
+			input: "a\n\n \n\t\nb\nc",
+			want:  []Block{{FirstLine: 1, Lines: []string{"a"}}, {FirstLine: 5, Lines: []string{"b", "c"}}},
+		},
+		{
+			name:  "crlf",
+			input: "a\r\n\r\nb\r\n",
+			want:  []Block{{FirstLine: 1, Lines: []string{"a"}}, {FirstLine: 3, Lines: []string{"b"}}},
+		},
+	}
+
+	for _, test := range tests {
+		scanned := []Block{}
+		err := ScanBlocks(context.Background(), strings.NewReader(test.input), func(block Block) error {
+			scanned = append(scanned, block)
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("%s: ScanBlocks: unexpected error %v", test.name, err)
+		} else if !reflect.DeepEqual(scanned, test.want) {
+			t.Errorf("%s: ScanBlocks: got %q, want %q", test.name, scanned, test.want)
+		}
+
+		// GroupBlocks must split lines already read the same way
+		lines, err := GetLines(strings.NewReader(test.input))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if grouped := GroupBlocks(lines); !reflect.DeepEqual(grouped, test.want) {
+			t.Errorf("%s: GroupBlocks: got %q, want %q", test.name, grouped, test.want)
+		}
+	}
+}
+
+func TestScanBlocksErrors(t *testing.T) {
+	input := "a\n\nb\nc\n\nd\n"
+	failure := errors.New("failure")
+
+	// errors are placed at a block's first line, even for the last block
+	for _, failing := range []string{"b", "d"} {
+		err := ScanBlocks(context.Background(), strings.NewReader(input), func(block Block) error {
+			if block.Lines[0] == failing {
+				return failure
+			}
+			return nil
+		})
+
+		want := map[string]string{"b": "3: failure", "d": "6: failure"}[failing]
+		if err == nil || err.Error() != want {
+			t.Errorf("failing block %s: got error %v, want %s", failing, err, want)
+		}
+	}
+
+	// stopping early is not an error, and no block after the one stopping is scanned
+	scanned := 0
+	err := ScanBlocks(context.Background(), strings.NewReader(input), func(Block) error {
+		scanned++
+		return ErrStopScan
+	})
+	if err != nil || scanned != 1 {
+		t.Errorf("got error %v after %d blocks, want no error after 1", err, scanned)
+	}
+}
@@ -0,0 +1,29 @@
+package fileutil
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// GZIP_MAGIC is how every gzip stream begins (RFC 1952)
+var GZIP_MAGIC = []byte{0x1f, 0x8b}
+
+// Decompress returns a reader of the decompressed contents of r if r is gzip-compressed, or of r itself otherwise
+// NOTE: only gzip is detected, since it is the only compression format archived inputs use that the standard library can read
+func Decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	// inputs too short to hold the magic bytes cannot be compressed
+	magic, err := br.Peek(len(GZIP_MAGIC))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if !bytes.Equal(magic, GZIP_MAGIC) {
+		return br, nil
+	}
+
+	return gzip.NewReader(br)
+}
@@ -0,0 +1,52 @@
+package fileutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// gzipped compresses text, failing the test if it cannot
+func gzipped(tb testing.TB, text string) []byte {
+	tb.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(zw, text); err != nil {
+		tb.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		tb.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  []string
+	}{
+		{name: "plain", input: []byte("a\nb\n"), want: []string{"a", "b"}},
+		{name: "gzip", input: gzipped(t, "a\r\nb"), want: []string{"a", "b"}},
+		{name: "shorter than magic", input: []byte{GZIP_MAGIC[0]}, want: []string{string(GZIP_MAGIC[:1])}},
+		{name: "half the magic", input: []byte{GZIP_MAGIC[0], 'a', '\n'}, want: []string{string(GZIP_MAGIC[:1]) + "a"}},
+	}
+
+	for _, test := range tests {
+		lines, err := GetLines(bytes.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		} else if !reflect.DeepEqual(lines, test.want) {
+			t.Errorf("%s: got lines %q, want %q", test.name, lines, test.want)
+		}
+	}
+
+	// a gzip header with no valid stream after it is an error, not plain text
+	if _, err := GetLines(bytes.NewReader(GZIP_MAGIC)); err == nil {
+		t.Errorf("got no error reading a truncated gzip stream")
+	}
+}
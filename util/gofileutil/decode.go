@@ -0,0 +1,183 @@
+package fileutil
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DECODE_TAG is the struct tag naming which capture group of a Decoder's pattern fills a field, e.g. `re:"flow_rate"`
+const DECODE_TAG = "re"
+
+// Decoder turns lines of input into records of type T by matching them against a regular expression
+// Each exported field of T tagged with DECODE_TAG is set from the named capture group of the same name
+// Supported field types are strings, signed and unsigned integers of any size, and slices of those (from comma-separated values)
+type Decoder[T any] struct {
+	re       *regexp.Regexp
+	bindings []fieldBinding
+}
+
+// fieldBinding links a field of the record type to a capture group of the pattern
+type fieldBinding struct {
+	field int
+	group int
+	name  string
+}
+
+// NewDecoder compiles pattern and checks every tagged field of T has a capture group it can be decoded from
+func NewDecoder[T any](pattern string) (*Decoder[T], error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var record T
+	t := reflect.TypeOf(record)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("decoder record type must be a struct, not %v", t)
+	}
+
+	d := new(Decoder[T])
+	d.re = re
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, tagged := f.Tag.Lookup(DECODE_TAG)
+		if !tagged {
+			continue
+		}
+
+		if !f.IsExported() {
+			return nil, fmt.Errorf("field %s is tagged for decoding but is not exported", f.Name)
+		}
+
+		if !isDecodableType(f.Type) {
+			return nil, fmt.Errorf("field %s has type %v, which cannot be decoded", f.Name, f.Type)
+		}
+
+		group := re.SubexpIndex(name)
+		if group < 0 {
+			return nil, fmt.Errorf("field %s is tagged with capture group '%s', which is not in pattern %s", f.Name, name, pattern)
+		}
+
+		d.bindings = append(d.bindings, fieldBinding{field: i, group: group, name: name})
+	}
+
+	return d, nil
+}
+
+// MustNewDecoder is like NewDecoder but panics if the decoder cannot be created, so it can be used to initialize package variables
+func MustNewDecoder[T any](pattern string) *Decoder[T] {
+	d, err := NewDecoder[T](pattern)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// Decode fills a new record from text
+// Errors are a *PositionError with the column of the offending value; the line number is left for the caller (or a line scanner) to attach
+func (d *Decoder[T]) Decode(text string) (T, error) {
+	var record T
+
+	match := d.re.FindStringSubmatchIndex(text)
+	if match == nil {
+		return record, Errorf(0, 0, "'%s' does not match %s: %w", text, d.re, ErrUnexpectedFormat)
+	}
+
+	v := reflect.ValueOf(&record).Elem()
+	for _, b := range d.bindings {
+		start, end := match[2*b.group], match[2*b.group+1]
+
+		// optional groups which did not participate in the match leave the field as its zero value
+		if start < 0 {
+			continue
+		}
+
+		if offset, err := setDecodedValue(v.Field(b.field), text[start:end]); err != nil {
+			return record, Errorf(0, start+offset+1, "%s: %w", b.name, err)
+		}
+	}
+
+	return record, nil
+}
+
+// DecodeAll decodes one record per line, stopping at the first line which cannot be decoded
+func (d *Decoder[T]) DecodeAll(lines []string) ([]T, error) {
+	records := make([]T, len(lines))
+
+	for i, line := range lines {
+		var err error
+		records[i], err = d.Decode(line)
+		if err != nil {
+			return nil, AtLine(err, i+1)
+		}
+	}
+
+	return records, nil
+}
+
+func isDecodableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Slice && isDecodableType(t.Elem())
+	}
+
+	return false
+}
+
+// setDecodedValue parses text into v according to v's type, which must satisfy isDecodableType
+// On failure, it returns the offset into text of the value which could not be parsed, e.g. one element of a slice
+func setDecodedValue(v reflect.Value, text string) (int, error) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return 0, err
+		}
+		v.SetInt(num)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return 0, err
+		}
+		v.SetUint(num)
+
+	case reflect.Slice:
+		// comma-separated values, with or without spaces after the commas
+		if len(strings.TrimSpace(text)) == 0 {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			return 0, nil
+		}
+
+		elems := strings.Split(text, ",")
+		slice := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+		elem_start := 0
+		for i, elem := range elems {
+			trimmed := strings.TrimSpace(elem)
+			if _, err := setDecodedValue(slice.Index(i), trimmed); err != nil {
+				return elem_start + len(elem) - len(strings.TrimLeftFunc(elem, unicode.IsSpace)), err
+			}
+			elem_start += len(elem) + len(",")
+		}
+		v.Set(slice)
+
+	default:
+		return 0, fmt.Errorf("cannot decode into %v", v.Type())
+	}
+
+	return 0, nil
+}
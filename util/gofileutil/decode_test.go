@@ -0,0 +1,123 @@
+package fileutil
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type decodedValve struct {
+	Name    string  `re:"name"`
+	Rate    int16   `re:"rate"`
+	Tunnels []uint8 `re:"tunnels"`
+	Label   string  `re:"label"`
+	Ignored string
+}
+
+const VALVE_PATTERN = `^Valve (?P<name>\w+) rate=(?P<rate>\S+)(?:; tunnels to (?P<tunnels>.*?))?(?: \((?P<label>\w+)\))?$`
+
+func TestDecode(t *testing.T) {
+	d := MustNewDecoder[decodedValve](VALVE_PATTERN)
+
+	tests := []struct {
+		text string
+		want decodedValve
+	}{
+		{text: "Valve AA rate=0", want: decodedValve{Name: "AA"}},
+		{text: "Valve BB rate=-13 (start)", want: decodedValve{Name: "BB", Rate: -13, Label: "start"}},
+		{text: "Valve CC rate=2; tunnels to 1,2, 3", want: decodedValve{Name: "CC", Rate: 2, Tunnels: []uint8{1, 2, 3}}},
+		{text: "Valve DD rate=2; tunnels to 255 (end)", want: decodedValve{Name: "DD", Rate: 2, Tunnels: []uint8{255}, Label: "end"}},
+		{text: "Valve EE rate=2; tunnels to ", want: decodedValve{Name: "EE", Rate: 2, Tunnels: []uint8{}}},
+	}
+
+	for _, test := range tests {
+		got, err := d.Decode(test.text)
+		if err != nil {
+			t.Errorf("'%s': unexpected error %v", test.text, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("'%s': got %+v, want %+v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	d := MustNewDecoder[decodedValve](VALVE_PATTERN)
+
+	tests := []struct {
+		text   string
+		column int
+		want   error
+	}{
+		{text: "Tunnel AA rate=0", column: 0, want: ErrUnexpectedFormat},
+		{text: "Valve AA rate=x", column: 15, want: strconvErr(t, "x")},
+		{text: "Valve AA rate=99999", column: 15},
+		// slices point at the element which failed, past any spaces before it
+		{text: "Valve AA rate=1; tunnels to x", column: 29},
+		{text: "Valve AA rate=1; tunnels to 1,2,  256", column: 35},
+		{text: "Valve AA rate=1; tunnels to 1,,2", column: 31},
+	}
+
+	for _, test := range tests {
+		_, err := d.Decode(test.text)
+
+		var pe *PositionError
+		if !errors.As(err, &pe) {
+			t.Errorf("'%s': got error %v, want a *PositionError", test.text, err)
+			continue
+		}
+
+		if pe.Line != 0 || pe.Column != test.column {
+			t.Errorf("'%s': got error at %d:%d (%v), want column %d", test.text, pe.Line, pe.Column, err, test.column)
+		}
+		if test.want != nil && !errors.Is(err, test.want) {
+			t.Errorf("'%s': got error %v, want %v", test.text, err, test.want)
+		}
+	}
+
+	// DecodeAll places errors at the line which failed
+	_, err := d.DecodeAll([]string{"Valve AA rate=0", "Valve BB rate=y"})
+	if err == nil || !strings.HasPrefix(err.Error(), "2:15: rate: ") {
+		t.Errorf("got DecodeAll error %v, want it at 2:15", err)
+	}
+}
+
+// strconvErr is the cause strconv reports for text which is not a number
+func strconvErr(tb testing.TB, text string) error {
+	tb.Helper()
+
+	var ne *strconv.NumError
+	if _, err := strconv.Atoi(text); !errors.As(err, &ne) {
+		tb.Fatalf("'%s' is a number", text)
+	}
+
+	return ne.Err
+}
+
+func TestNewDecoderErrors(t *testing.T) {
+	type unexported struct {
+		name string `re:"name"`
+	}
+	if _, err := NewDecoder[unexported](`(?P<name>\w+)`); err == nil {
+		t.Errorf("unexported field: got no error")
+	}
+
+	type missing struct {
+		Name string `re:"name"`
+	}
+	if _, err := NewDecoder[missing](`(?P<other>\w+)`); err == nil {
+		t.Errorf("missing capture group: got no error")
+	}
+
+	type nested struct {
+		Grid [][]int `re:"grid"`
+	}
+	if _, err := NewDecoder[nested](`(?P<grid>.*)`); err == nil {
+		t.Errorf("slice of slices: got no error")
+	}
+
+	if _, err := NewDecoder[int](`.*`); err == nil {
+		t.Errorf("non-struct record: got no error")
+	}
+}
@@ -0,0 +1,202 @@
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnexpectedFormat is the cause of errors for input that does not match what a parser expects
+var ErrUnexpectedFormat = errors.New("unexpected input format")
+
+// PositionError reports a problem with input at a particular place; any position it does not know is left as zero
+type PositionError struct {
+	File   string
+	Line   int // 1-indexed
+	Column int // 1-indexed
+	Err    error
+}
+
+func (e *PositionError) Error() string {
+	var sb strings.Builder
+
+	// formatted like compiler output, e.g. "input.txt:3:14: cause"
+	position := []string{}
+	if len(e.File) > 0 {
+		position = append(position, e.File)
+	}
+	if e.Line > 0 {
+		position = append(position, strconv.Itoa(e.Line))
+
+		if e.Column > 0 {
+			position = append(position, strconv.Itoa(e.Column))
+		}
+	}
+
+	if len(position) > 0 {
+		sb.WriteString(strings.Join(position, ":"))
+		sb.WriteString(": ")
+	}
+
+	if e.Err != nil {
+		sb.WriteString(e.Err.Error())
+	} else {
+		sb.WriteString("unknown error")
+	}
+
+	return sb.String()
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// Errorf creates a PositionError at the given line and column, with a cause formatted as by fmt.Errorf (so %w is supported)
+func Errorf(line, column int, format string, a ...any) error {
+	return &PositionError{Line: line, Column: column, Err: fmt.Errorf(format, a...)}
+}
+
+// ErrorList collects every problem found in an input, so they can all be reported at once rather than one per run
+type ErrorList []error
+
+// Add appends err to the list, unless it is nil
+func (l *ErrorList) Add(err error) {
+	if err != nil {
+		*l = append(*l, err)
+	}
+}
+
+// Err returns the list as an error, or nil if it is empty
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+
+	return l
+}
+
+// Error gives one problem per line
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any error in the list matches target, so errors.Is looks through the list
+func (l ErrorList) Is(target error) bool {
+	for _, err := range l {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error in the list which errors.As can set target to
+func (l ErrorList) As(target any) bool {
+	for _, err := range l {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// repositioned is an error whose PositionError, somewhere down its chain, has been replaced by a copy at a new position
+// Its message is the original's with the position rewritten, and errors.As finds the copy first
+type repositioned struct {
+	msg string
+	pe  *PositionError
+	err error
+}
+
+func (r *repositioned) Error() string { return r.msg }
+func (r *repositioned) Unwrap() error { return r.err }
+
+func (r *repositioned) As(target any) bool {
+	if pe, ok := target.(**PositionError); ok {
+		*pe = r.pe
+		return true
+	}
+	return false
+}
+
+// withPosition returns err with its position changed by update, or placed at the position made by added if it has none (and added is not nil); nil stays nil
+// err itself is never changed, so errors which are shared or reused keep their positions: the PositionError found is copied, and so is an ErrorList
+func withPosition(err error, update func(pe *PositionError), added func() *PositionError) error {
+	if err == nil {
+		return nil
+	}
+
+	var l ErrorList
+	if errors.As(err, &l) {
+		updated := make(ErrorList, len(l))
+		for i := range l {
+			updated[i] = withPosition(l[i], update, added)
+		}
+		return updated
+	}
+
+	var pe *PositionError
+	if !errors.As(err, &pe) {
+		if added == nil {
+			return err
+		}
+
+		new_pe := added()
+		new_pe.Err = err
+		return new_pe
+	}
+
+	moved := *pe
+	update(&moved)
+	if moved.File == pe.File && moved.Line == pe.Line && moved.Column == pe.Column {
+		return err
+	}
+	if err == error(pe) {
+		return &moved
+	}
+
+	return &repositioned{msg: strings.Replace(err.Error(), pe.Error(), moved.Error(), 1), pe: &moved, err: err}
+}
+
+// AtLine attaches a line number to err, unless it already has one; nil stays nil
+// Every error of an ErrorList is given the line number
+func AtLine(err error, line int) error {
+	return withPosition(err,
+		func(pe *PositionError) {
+			if pe.Line == 0 {
+				pe.Line = line
+			}
+		},
+		func() *PositionError { return &PositionError{Line: line} })
+}
+
+// WithFile attaches a file name to err, unless it already has one; nil stays nil
+// Every error of an ErrorList is given the file name
+func WithFile(err error, name string) error {
+	return withPosition(err,
+		func(pe *PositionError) {
+			if len(pe.File) == 0 {
+				pe.File = name
+			}
+		},
+		func() *PositionError { return &PositionError{File: name} })
+}
+
+// ShiftColumn moves err's column along by offset, e.g. when it was found in part of a line; errors with no column are left as they are
+func ShiftColumn(err error, offset int) error {
+	return withPosition(err,
+		func(pe *PositionError) {
+			if pe.Column > 0 {
+				pe.Column += offset
+			}
+		},
+		nil)
+}
@@ -0,0 +1,37 @@
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPositionsAreNotShared(t *testing.T) {
+	shared := Errorf(0, 5, "shared: %w", ErrUnexpectedFormat)
+	wrapped := fmt.Errorf("while parsing: %w", shared)
+
+	tests := []struct {
+		name string
+		got  error
+		want string
+	}{
+		{name: "AtLine", got: AtLine(shared, 3), want: "3:5: shared: unexpected input format"},
+		{name: "WithFile", got: WithFile(AtLine(shared, 4), "input.txt"), want: "input.txt:4:5: shared: unexpected input format"},
+		{name: "ShiftColumn", got: ShiftColumn(AtLine(shared, 1), 10), want: "1:15: shared: unexpected input format"},
+		{name: "wrapped", got: AtLine(wrapped, 7), want: "while parsing: 7:5: shared: unexpected input format"},
+		{name: "list", got: AtLine(ErrorList{shared, errors.New("other")}, 2), want: "2:5: shared: unexpected input format\n2: other"},
+	}
+
+	for _, test := range tests {
+		if test.got.Error() != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, test.got, test.want)
+		}
+		if !errors.Is(test.got, ErrUnexpectedFormat) {
+			t.Errorf("%s: %v no longer wraps %v", test.name, test.got, ErrUnexpectedFormat)
+		}
+	}
+
+	if pe := shared.(*PositionError); pe.File != "" || pe.Line != 0 || pe.Column != 5 {
+		t.Errorf("the shared error was moved to %s:%d:%d", pe.File, pe.Line, pe.Column)
+	}
+}
@@ -5,27 +5,45 @@ fileutil.go: Laura Galbraith
 package fileutil
 
 import (
-	"bufio"
-	"fmt"
-	"os"
+	"context"
+	"io"
+	"io/fs"
 )
 
+// GetLinesFromFile returns every line of the named file ("-" for stdin), decompressing it first if it is gzip-compressed
+// Errors are not printed, so callers decide how to report them
 func GetLinesFromFile(name string) ([]string, error) {
-	f, err := os.Open(name)
+	r, err := OpenInput(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	lines, err := GetLines(r)
+	return lines, WithFile(err, InputDisplayName(name))
+}
+
+// GetLinesFromFS returns every line of the named file within fsys, e.g. an embed.FS or fstest.MapFS
+func GetLinesFromFS(fsys fs.FS, name string) ([]string, error) {
+	f, err := fsys.Open(name)
 	if err != nil {
-		fmt.Printf("Error opening file %s: %v\n", name, err)
 		return nil, err
 	}
 	defer f.Close()
 
+	lines, err := GetLines(f)
+	return lines, WithFile(err, name)
+}
+
+// GetLines returns every line that can be read from r
+func GetLines(r io.Reader) ([]string, error) {
 	lines := []string{}
-	s := bufio.NewScanner(f)
-	for s.Scan() {
-		lines = append(lines, s.Text())
-	}
+	err := ScanLines(context.Background(), r, func(line Line) error {
+		lines = append(lines, line.Text)
+		return nil
+	})
 
-	if err := s.Err(); err != nil {
-		fmt.Printf("Error reading file %s: %v\n", name, err)
+	if err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,79 @@
+package fileutil
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var TEST_FS = fstest.MapFS{
+	"input.txt":     &fstest.MapFile{Data: []byte("a\nb\n")},
+	"sub/crlf.txt":  &fstest.MapFile{Data: []byte("c\r\nd")},
+	"sub/empty.txt": &fstest.MapFile{Data: []byte{}},
+}
+
+func TestGetLinesFromFS(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "input.txt", want: []string{"a", "b"}},
+		{name: "sub/crlf.txt", want: []string{"c", "d"}},
+		{name: "sub/empty.txt", want: []string{}},
+	}
+
+	for _, test := range tests {
+		lines, err := GetLinesFromFS(TEST_FS, test.name)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		} else if !reflect.DeepEqual(lines, test.want) {
+			t.Errorf("%s: got lines %q, want %q", test.name, lines, test.want)
+		}
+
+		// reading the same file from a reader gives the same lines
+		data, _ := TEST_FS.ReadFile(test.name)
+		if lines, err := GetLines(strings.NewReader(string(data))); err != nil || !reflect.DeepEqual(lines, test.want) {
+			t.Errorf("%s: GetLines got %q (error %v), want %q", test.name, lines, err, test.want)
+		}
+	}
+
+	if _, err := GetLinesFromFS(TEST_FS, "missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: got error %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestScanLinesFromFS(t *testing.T) {
+	// errors name the file within the fs.FS they are from
+	err := ScanLinesFromFS(context.Background(), TEST_FS, "sub/crlf.txt", func(line Line) error {
+		if line.Text == "d" {
+			return ErrUnexpectedFormat
+		}
+		return nil
+	})
+
+	want := "sub/crlf.txt:2: " + ErrUnexpectedFormat.Error()
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %s", err, want)
+	}
+	if !errors.Is(err, ErrUnexpectedFormat) {
+		t.Errorf("error %v does not wrap %v", err, ErrUnexpectedFormat)
+	}
+}
+
+func TestInputDisplayName(t *testing.T) {
+	tests := map[string]string{
+		STDIN_NAME:      "<stdin>",
+		"input.txt":     "input.txt",
+		"dir/input.txt": "dir/input.txt",
+	}
+
+	for name, want := range tests {
+		if got := InputDisplayName(name); got != want {
+			t.Errorf("'%s': got %s, want %s", name, got, want)
+		}
+	}
+}
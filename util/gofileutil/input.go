@@ -0,0 +1,29 @@
+package fileutil
+
+import (
+	"io"
+	"os"
+)
+
+// STDIN_NAME is the input name that refers to standard input rather than a file
+const STDIN_NAME = "-"
+
+// OpenInput opens the named file for reading, or returns standard input if name is STDIN_NAME
+// NOTE: failing to open the file results in an *fs.PathError, which already names the file
+func OpenInput(name string) (io.ReadCloser, error) {
+	if name == STDIN_NAME {
+		// the caller closing stdin would be surprising, so closing is a no-op
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(name)
+}
+
+// InputDisplayName returns how the named input should be referred to in messages
+func InputDisplayName(name string) string {
+	if name == STDIN_NAME {
+		return "<stdin>"
+	}
+
+	return name
+}
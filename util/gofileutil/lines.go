@@ -0,0 +1,88 @@
+package fileutil
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// Line is a single line of input, without its line ending
+type Line struct {
+	Number int // 1-indexed
+	Text   string
+}
+
+// LineFunc is called once per line while scanning; returning ErrStopScan ends the scan early without error
+type LineFunc func(line Line) error
+
+// ErrStopScan can be returned by a LineFunc to stop scanning before the end of the input
+var ErrStopScan = errors.New("stop scan")
+
+// ScanLinesFromFile streams the lines of the named file ("-" for stdin) to fn one at a time, so the whole file never has to be held in memory
+func ScanLinesFromFile(ctx context.Context, name string, fn LineFunc) error {
+	r, err := OpenInput(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return WithFile(ScanLines(ctx, r, fn), InputDisplayName(name))
+}
+
+// ScanLinesFromFS streams the lines of the named file within fsys to fn one at a time
+func ScanLinesFromFS(ctx context.Context, fsys fs.FS, name string, fn LineFunc) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return WithFile(ScanLines(ctx, f, fn), name)
+}
+
+// ScanLines reads r line by line until EOF, cancellation of ctx, or fn asking to stop; gzip-compressed input is decompressed transparently
+// Errors from reading or from fn are returned as a *PositionError holding the line number
+func ScanLines(ctx context.Context, r io.Reader, fn LineFunc) error {
+	// compressed input is read as if it had been decompressed first
+	r, err := Decompress(r)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: bufio.Scanner caps lines at 64KB by default, which is too small for single-line inputs like day 17's jet pattern, so read with no limit instead
+	br := bufio.NewReader(r)
+
+	for line_num := 1; ; line_num++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		text, read_err := br.ReadString('\n')
+		if read_err != nil && read_err != io.EOF {
+			return AtLine(read_err, line_num)
+		}
+
+		// the final line of a file may or may not end with a newline; nothing at all after the last newline is not a line
+		if len(text) == 0 && read_err == io.EOF {
+			return nil
+		}
+
+		// drop the line ending, matching what bufio.ScanLines would produce
+		text = strings.TrimSuffix(text, "\n")
+		text = strings.TrimSuffix(text, "\r")
+
+		if err := fn(Line{Number: line_num, Text: text}); err != nil {
+			if errors.Is(err, ErrStopScan) {
+				return nil
+			}
+			return AtLine(err, line_num)
+		}
+
+		if read_err == io.EOF {
+			return nil
+		}
+	}
+}
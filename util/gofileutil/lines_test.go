@@ -0,0 +1,78 @@
+package fileutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		stop_at int // line number whose LineFunc returns ErrStopScan; 0 for none
+		want    []string
+	}{
+		{name: "empty", input: "", want: []string{}},
+		{name: "final newline", input: "a\nb\n", want: []string{"a", "b"}},
+		{name: "no final newline", input: "a\nb", want: []string{"a", "b"}},
+		{name: "crlf", input: "a\r\nb\r\n", want: []string{"a", "b"}},
+		{name: "crlf without final newline", input: "a\r\nb", want: []string{"a", "b"}},
+		{name: "blank lines kept", input: "\na\n\n", want: []string{"", "a", ""}},
+		{name: "stop scan", input: "a\nb\nc\n", stop_at: 2, want: []string{"a", "b"}},
+	}
+
+	for _, test := range tests {
+		lines := []string{}
+		err := ScanLines(context.Background(), strings.NewReader(test.input), func(line Line) error {
+			if line.Number != len(lines)+1 {
+				return fmt.Errorf("got line number %d after %d lines", line.Number, len(lines))
+			}
+
+			lines = append(lines, line.Text)
+			if line.Number == test.stop_at {
+				return ErrStopScan
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		} else if !reflect.DeepEqual(lines, test.want) {
+			t.Errorf("%s: got lines %q, want %q", test.name, lines, test.want)
+		}
+	}
+}
+
+func TestScanLinesErrors(t *testing.T) {
+	failure := errors.New("failure")
+
+	// errors from the LineFunc are placed at the line it was given
+	err := ScanLines(context.Background(), strings.NewReader("a\nb\nc\n"), func(line Line) error {
+		if line.Number == 2 {
+			return failure
+		}
+		return nil
+	})
+
+	var pe *PositionError
+	if !errors.Is(err, failure) || !errors.As(err, &pe) || pe.Line != 2 {
+		t.Errorf("got error %v, want %v at line 2", err, failure)
+	}
+
+	// a cancelled scan stops before reading anything
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	err = ScanLines(ctx, strings.NewReader("a\n"), func(Line) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || called {
+		t.Errorf("got error %v (called: %v) after cancelling, want %v", err, called, context.Canceled)
+	}
+}
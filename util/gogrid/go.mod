@@ -0,0 +1,7 @@
+module gogrid
+
+go 1.19
+
+require github.com/lauragalbraith/AdventOfCode2022/util/gofileutil v0.0.0-00010101000000-000000000000
+
+replace github.com/lauragalbraith/AdventOfCode2022/util/gofileutil => ../gofileutil
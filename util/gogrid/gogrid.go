@@ -0,0 +1,254 @@
+/*
+Package grid provides a 2D grid of cells for Advent of Code puzzles whose input is a map of characters
+gogrid.go: Laura Galbraith
+*/
+package grid
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+)
+
+// Point is a position in a grid; row 0 is the top line of input, col 0 is the leftmost character
+type Point struct {
+	Row, Col int
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{Row: p.Row + q.Row, Col: p.Col + q.Col}
+}
+
+// Sub is the offset from q to p
+func (p Point) Sub(q Point) Point {
+	return Point{Row: p.Row - q.Row, Col: p.Col - q.Col}
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d,%d)", p.Row, p.Col)
+}
+
+// Neighbor offsets, clockwise starting from up
+var (
+	NEIGHBORS_4 = []Point{{-1, 0}, {0, 1}, {1, 0}, {0, -1}}
+	NEIGHBORS_8 = []Point{{-1, 0}, {-1, 1}, {0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}}
+)
+
+// Grid is a rectangular grid of cells of any comparable type, e.g. runes for a character map or ints for tile types
+type Grid[T comparable] struct {
+	cells [][]T
+}
+
+// New creates a grid of the given size with every cell set to fill
+func New[T comparable](rows, cols int, fill T) *Grid[T] {
+	g := new(Grid[T])
+
+	g.cells = make([][]T, rows)
+	for row := range g.cells {
+		g.cells[row] = make([]T, cols)
+		for col := range g.cells[row] {
+			g.cells[row][col] = fill
+		}
+	}
+
+	return g
+}
+
+// FromLines creates a character grid with one row per line; all lines must be the same length
+func FromLines(lines []string) (*Grid[rune], error) {
+	g := new(Grid[rune])
+
+	g.cells = make([][]rune, len(lines))
+	for row, line := range lines {
+		if row > 0 && utf8.RuneCountInString(line) != len(g.cells[0]) {
+			return nil, fileutil.Errorf(row+1, 0, "row has %d cells instead of %d: %w", utf8.RuneCountInString(line), len(g.cells[0]), fileutil.ErrUnexpectedFormat)
+		}
+
+		g.cells[row] = []rune(line)
+	}
+
+	return g, nil
+}
+
+// FromReader creates a character grid from every line that can be read from r
+func FromReader(r io.Reader) (*Grid[rune], error) {
+	lines, err := fileutil.GetLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromLines(lines)
+}
+
+// FromFile creates a character grid from the named file ("-" for stdin)
+func FromFile(name string) (*Grid[rune], error) {
+	lines, err := fileutil.GetLinesFromFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := FromLines(lines)
+	return g, fileutil.WithFile(err, fileutil.InputDisplayName(name))
+}
+
+func (g *Grid[T]) Rows() int {
+	return len(g.cells)
+}
+
+func (g *Grid[T]) Cols() int {
+	if len(g.cells) == 0 {
+		return 0
+	}
+
+	return len(g.cells[0])
+}
+
+func (g *Grid[T]) InBounds(p Point) bool {
+	return p.Row >= 0 && p.Row < g.Rows() && p.Col >= 0 && p.Col < g.Cols()
+}
+
+// Get returns the value at p, and false if p is outside the grid
+func (g *Grid[T]) Get(p Point) (T, bool) {
+	if !g.InBounds(p) {
+		var zero T
+		return zero, false
+	}
+
+	return g.cells[p.Row][p.Col], true
+}
+
+// Set changes the value at p, returning false (and changing nothing) if p is outside the grid
+func (g *Grid[T]) Set(p Point, val T) bool {
+	if !g.InBounds(p) {
+		return false
+	}
+
+	g.cells[p.Row][p.Col] = val
+	return true
+}
+
+// AddRows adds n rows after the last, as wide as the others and with every cell set to fill, for grids which grow as a puzzle goes on
+func (g *Grid[T]) AddRows(n int, fill T) {
+	cols := g.Cols()
+
+	for i := 0; i < n; i++ {
+		row := make([]T, cols)
+		for col := range row {
+			row[col] = fill
+		}
+		g.cells = append(g.cells, row)
+	}
+}
+
+// Neighbors returns the points reached from p by each of the given offsets, leaving out any outside the grid
+func (g *Grid[T]) Neighbors(p Point, offsets []Point) []Point {
+	neighbors := make([]Point, 0, len(offsets))
+
+	for _, offset := range offsets {
+		n := p.Add(offset)
+		if g.InBounds(n) {
+			neighbors = append(neighbors, n)
+		}
+	}
+
+	return neighbors
+}
+
+// Neighbors4 returns the orthogonal neighbors of p within the grid
+func (g *Grid[T]) Neighbors4(p Point) []Point {
+	return g.Neighbors(p, NEIGHBORS_4)
+}
+
+// Neighbors8 returns the orthogonal and diagonal neighbors of p within the grid
+func (g *Grid[T]) Neighbors8(p Point) []Point {
+	return g.Neighbors(p, NEIGHBORS_8)
+}
+
+// Each calls fn for every cell, row by row from the top left
+func (g *Grid[T]) Each(fn func(p Point, val T)) {
+	for row := range g.cells {
+		for col, val := range g.cells[row] {
+			fn(Point{Row: row, Col: col}, val)
+		}
+	}
+}
+
+// Find returns the first cell (row by row from the top left) holding val, and false if there is none
+func (g *Grid[T]) Find(val T) (Point, bool) {
+	for row := range g.cells {
+		for col := range g.cells[row] {
+			if g.cells[row][col] == val {
+				return Point{Row: row, Col: col}, true
+			}
+		}
+	}
+
+	return Point{}, false
+}
+
+// FindAll returns every cell holding val, row by row from the top left
+func (g *Grid[T]) FindAll(val T) []Point {
+	found := []Point{}
+
+	g.Each(func(p Point, cell T) {
+		if cell == val {
+			found = append(found, p)
+		}
+	})
+
+	return found
+}
+
+func (g *Grid[T]) DeepCopy() *Grid[T] {
+	if g == nil {
+		return nil
+	}
+
+	new_g := new(Grid[T])
+
+	new_g.cells = make([][]T, len(g.cells))
+	for row := range g.cells {
+		new_g.cells[row] = make([]T, len(g.cells[row]))
+		copy(new_g.cells[row], g.cells[row])
+	}
+
+	return new_g
+}
+
+// Render draws the grid as text, one line per row, using char to pick how each cell is drawn
+func (g *Grid[T]) Render(char func(p Point, val T) rune) string {
+	var sb strings.Builder
+
+	for row := range g.cells {
+		for col, val := range g.cells[row] {
+			sb.WriteRune(char(Point{Row: row, Col: col}, val))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// String draws character grids as they appeared in the input; other cell types are drawn with their default format
+func (g *Grid[T]) String() string {
+	var sb strings.Builder
+
+	for row := range g.cells {
+		for _, val := range g.cells[row] {
+			switch v := any(val).(type) {
+			case rune:
+				sb.WriteRune(v)
+			case byte:
+				sb.WriteByte(v)
+			default:
+				fmt.Fprint(&sb, v)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
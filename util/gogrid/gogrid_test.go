@@ -0,0 +1,193 @@
+package grid
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+)
+
+// TEST_LINES is a small map with its '#' cells on the edges and in a corner
+var TEST_LINES = []string{
+	"#..#",
+	".S..",
+	"...#",
+}
+
+func testGrid(t *testing.T) *Grid[rune] {
+	t.Helper()
+
+	g, err := FromLines(TEST_LINES)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+func TestFromLines(t *testing.T) {
+	g := testGrid(t)
+
+	if g.Rows() != 3 || g.Cols() != 4 {
+		t.Errorf("got %dx%d grid, want 3x4", g.Rows(), g.Cols())
+	}
+	if got, want := g.String(), strings.Join(TEST_LINES, "\n")+"\n"; got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	empty, err := FromLines(nil)
+	if err != nil || empty.Rows() != 0 || empty.Cols() != 0 {
+		t.Errorf("got %dx%d grid (error %v) from no lines, want 0x0", empty.Rows(), empty.Cols(), err)
+	}
+
+	// cells are runes, so multi-byte characters count once
+	wide, err := FromLines([]string{"é.", ".é"})
+	if err != nil || wide.Cols() != 2 {
+		t.Errorf("got %d columns (error %v) for 2 runes per line, want 2", wide.Cols(), err)
+	}
+}
+
+func TestFromLinesRagged(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{name: "short row", lines: []string{"...", "..", "..."}, want: "2: row has 2 cells instead of 3: unexpected input format"},
+		{name: "long last row", lines: []string{"..", "..", "..."}, want: "3: row has 3 cells instead of 2: unexpected input format"},
+		{name: "empty row", lines: []string{".", ""}, want: "2: row has 0 cells instead of 1: unexpected input format"},
+	}
+
+	for _, test := range tests {
+		g, err := FromLines(test.lines)
+		if err == nil {
+			t.Errorf("%s: got grid\n%s\nwant an error", test.name, g)
+			continue
+		}
+		if err.Error() != test.want {
+			t.Errorf("%s: got error %q, want %q", test.name, err, test.want)
+		}
+		if !errors.Is(err, fileutil.ErrUnexpectedFormat) {
+			t.Errorf("%s: %v does not wrap %v", test.name, err, fileutil.ErrUnexpectedFormat)
+		}
+	}
+
+	// files name themselves in the error
+	name := filepath.Join(t.TempDir(), "ragged.txt")
+	if err := os.WriteFile(name, []byte("..\n.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromFile(name); err == nil || !strings.HasPrefix(err.Error(), name+":2: ") {
+		t.Errorf("got error %v, want it at %s:2", err, name)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	g := testGrid(t)
+
+	if val, ok := g.Get(Point{Row: 1, Col: 1}); !ok || val != 'S' {
+		t.Errorf("got %c (in bounds %v) at (1,1), want S", val, ok)
+	}
+	if !g.Set(Point{Row: 2, Col: 0}, 'E') {
+		t.Error("could not set (2,0)")
+	}
+	if val, _ := g.Get(Point{Row: 2, Col: 0}); val != 'E' {
+		t.Errorf("got %c at (2,0) after setting it, want E", val)
+	}
+
+	before := g.String()
+	for _, p := range []Point{{Row: -1, Col: 0}, {Row: 0, Col: -1}, {Row: 3, Col: 0}, {Row: 0, Col: 4}, {Row: 3, Col: 4}} {
+		if val, ok := g.Get(p); ok || val != 0 {
+			t.Errorf("got %q (in bounds %v) at %v, want the zero value outside the grid", val, ok, p)
+		}
+		if g.Set(p, 'X') {
+			t.Errorf("set %v, which is outside the grid", p)
+		}
+	}
+	if g.String() != before {
+		t.Errorf("setting outside the grid changed it to\n%s", g)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	g := testGrid(t)
+
+	tests := []struct {
+		name string
+		got  []Point
+		want []Point
+	}{
+		{name: "4 in the middle", got: g.Neighbors4(Point{Row: 1, Col: 1}), want: []Point{{0, 1}, {1, 2}, {2, 1}, {1, 0}}},
+		{name: "4 in a corner", got: g.Neighbors4(Point{Row: 0, Col: 0}), want: []Point{{0, 1}, {1, 0}}},
+		{name: "4 on an edge", got: g.Neighbors4(Point{Row: 2, Col: 2}), want: []Point{{1, 2}, {2, 3}, {2, 1}}},
+		{name: "8 in the middle", got: g.Neighbors8(Point{Row: 1, Col: 2}), want: []Point{{0, 2}, {0, 3}, {1, 3}, {2, 3}, {2, 2}, {2, 1}, {1, 1}, {0, 1}}},
+		{name: "8 in a corner", got: g.Neighbors8(Point{Row: 2, Col: 3}), want: []Point{{1, 3}, {2, 2}, {1, 2}}},
+		{name: "8 on an edge", got: g.Neighbors8(Point{Row: 0, Col: 1}), want: []Point{{0, 2}, {1, 2}, {1, 1}, {1, 0}, {0, 0}}},
+		{name: "outside the grid", got: g.Neighbors4(Point{Row: -1, Col: 0}), want: []Point{{0, 0}}},
+		{name: "custom offsets", got: g.Neighbors(Point{Row: 0, Col: 0}, []Point{{2, 3}, {3, 0}}), want: []Point{{2, 3}}},
+	}
+
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	g := testGrid(t)
+
+	if p, found := g.Find('S'); !found || p != (Point{Row: 1, Col: 1}) {
+		t.Errorf("got 'S' at %v (found %v), want (1,1)", p, found)
+	}
+	if p, found := g.Find('#'); !found || p != (Point{Row: 0, Col: 0}) {
+		t.Errorf("got the first '#' at %v (found %v), want (0,0)", p, found)
+	}
+	if p, found := g.Find('E'); found {
+		t.Errorf("found 'E' at %v, which is not in the grid", p)
+	}
+
+	want := []Point{{0, 0}, {0, 3}, {2, 3}}
+	if got := g.FindAll('#'); !reflect.DeepEqual(got, want) {
+		t.Errorf("got '#' at %v, want %v", got, want)
+	}
+	if got := g.FindAll('E'); got == nil || len(got) != 0 {
+		t.Errorf("got %#v for a value not in the grid, want an empty list", got)
+	}
+}
+
+func TestAddRows(t *testing.T) {
+	g := testGrid(t)
+
+	g.AddRows(2, '~')
+	want := strings.Join(TEST_LINES, "\n") + "\n~~~~\n~~~~\n"
+	if g.Rows() != 5 || g.Cols() != 4 || g.String() != want {
+		t.Errorf("got %dx%d grid\n%s\nwant 5x4\n%s", g.Rows(), g.Cols(), g, want)
+	}
+	if !g.Set(Point{Row: 4, Col: 3}, '#') {
+		t.Error("could not set a cell in an added row")
+	}
+
+	g.AddRows(0, '~')
+	if g.Rows() != 5 {
+		t.Errorf("got %d rows after adding none, want 5", g.Rows())
+	}
+
+	// copies grow on their own
+	c := g.DeepCopy()
+	c.AddRows(1, '.')
+	if c.Rows() != 6 || g.Rows() != 5 {
+		t.Errorf("got %d rows in the copy and %d in the original, want 6 and 5", c.Rows(), g.Rows())
+	}
+
+	// a grid made by New grows too, with the width it was made with
+	n := New(1, 3, 0)
+	n.AddRows(1, 7)
+	if got := n.String(); got != "000\n777\n" {
+		t.Errorf("got\n%s\nwant\n000\n777", got)
+	}
+}
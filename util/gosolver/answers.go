@@ -0,0 +1,86 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+)
+
+// ANSWERS_SUFFIX is added to an input's name (without extension) to find its expected answers, e.g. input.txt -> input_answers.txt
+// The file uses the same lines as FORMAT_TEXT output ("Part 1 answer: 42"), so it can be created by saving a trusted run
+const ANSWERS_SUFFIX = "_answers"
+
+// ExpectedAnswer is a single line of an expected-answers file
+type ExpectedAnswer struct {
+	Part   int    `re:"part"`
+	Answer string `re:"answer"`
+}
+
+var answer_decoder = fileutil.MustNewDecoder[ExpectedAnswer](`^Part (?P<part>\d+) answer: (?P<answer>.*)$`)
+
+// AnswersFileName returns where the expected answers for the named input are kept
+func AnswersFileName(input_name string) (string, error) {
+	if input_name == fileutil.STDIN_NAME {
+		return "", errors.New("standard input has no expected-answers file")
+	}
+
+	ext := filepath.Ext(input_name)
+	return strings.TrimSuffix(input_name, ext) + ANSWERS_SUFFIX + ext, nil
+}
+
+// ReadExpectedAnswers returns the expected answer for each part listed in the named file
+func ReadExpectedAnswers(answers_name string) (map[int]string, error) {
+	lines, err := fileutil.GetLinesFromFile(answers_name)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[int]string)
+	for i, line := range lines {
+		// allow blank lines, like those between parts in some older output
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		a, err := answer_decoder.Decode(line)
+		if err != nil {
+			return nil, fileutil.WithFile(fileutil.AtLine(err, i+1), answers_name)
+		}
+
+		if _, repeated := expected[a.Part]; repeated {
+			return nil, fileutil.WithFile(fileutil.Errorf(i+1, 0, "part %d is listed more than once", a.Part), answers_name)
+		}
+
+		expected[a.Part] = a.Answer
+	}
+
+	return expected, nil
+}
+
+// verification tracks how the answers of a run compare to the expected ones
+type verification struct {
+	expected map[int]string
+	failed   []int
+}
+
+// check returns whether answer is expected for part; found is false if no answer is expected
+func (v *verification) check(part int, answer string) (pass bool, expected string, found bool) {
+	expected, found = v.expected[part]
+	if found && expected != answer {
+		v.failed = append(v.failed, part)
+		return false, expected, true
+	}
+
+	return found, expected, found
+}
+
+func (v *verification) err() error {
+	if len(v.failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("answers did not match the expected answers for parts %v", v.failed)
+}
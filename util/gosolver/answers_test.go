@@ -0,0 +1,124 @@
+package solver
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAnswersFileName(t *testing.T) {
+	tests := map[string]string{
+		"input.txt":             "input_answers.txt",
+		"dir/example_input.txt": "dir/example_input_answers.txt",
+		"input":                 "input_answers",
+	}
+
+	for input_name, want := range tests {
+		if got, err := AnswersFileName(input_name); err != nil || got != want {
+			t.Errorf("'%s': got %s (error %v), want %s", input_name, got, err, want)
+		}
+	}
+
+	if _, err := AnswersFileName("-"); err == nil {
+		t.Error("got no error for standard input")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers string // contents of input_answers.txt; empty for none
+		mainTest
+	}{
+		{
+			name:     "pass",
+			answers:  "Part 1 answer: 6\nPart 2 answer: a+b+c\n",
+			mainTest: mainTest{stdout: "Part 1 answer: 6 (PASS)\nPart 2 answer: a+b+c (PASS)\n"},
+		},
+		{
+			name:     "blank lines",
+			answers:  "\nPart 1 answer: 6\n\nPart 2 answer: a+b+c\n\n",
+			mainTest: mainTest{stdout: "Part 1 answer: 6 (PASS)\nPart 2 answer: a+b+c (PASS)\n"},
+		},
+		{
+			name:     "plain format",
+			answers:  "Part 1 answer: 6\nPart 2 answer: a+b+c\n",
+			mainTest: mainTest{args: []string{"-format", "plain"}, stdout: "6 (PASS)\na+b+c (PASS)\n"},
+		},
+		{
+			name:    "fail",
+			answers: "Part 1 answer: 7\nPart 2 answer: a+b+c\n",
+			mainTest: mainTest{
+				code:   EXIT_FAILED,
+				stdout: "Part 1 answer: 6 (FAIL: expected 7)\nPart 2 answer: a+b+c (PASS)\n",
+				stderr: "day 99: answers did not match the expected answers for parts [1]\n",
+			},
+		},
+		{
+			name:    "fail every part",
+			answers: "Part 1 answer: 7\nPart 2 answer: a\n",
+			mainTest: mainTest{
+				code:   EXIT_FAILED,
+				stdout: "Part 1 answer: 6 (FAIL: expected 7)\nPart 2 answer: a+b+c (FAIL: expected a)\n",
+				stderr: "for parts [1 2]",
+			},
+		},
+		{
+			name:     "part not listed",
+			answers:  "Part 1 answer: 6\n",
+			mainTest: mainTest{stdout: "Part 1 answer: 6 (PASS)\nPart 2 answer: a+b+c (no expected answer)\n"},
+		},
+		{
+			name:     "only parts run are checked",
+			answers:  "Part 1 answer: 6\nPart 2 answer: wrong\n",
+			mainTest: mainTest{args: []string{"-part", "1"}, stdout: "Part 1 answer: 6 (PASS)\n"},
+		},
+		{
+			name:     "no answers file",
+			mainTest: mainTest{code: EXIT_FAILED, stderr: "input_answers.txt: no such file or directory"},
+		},
+		{
+			name:     "part listed twice",
+			answers:  "Part 1 answer: 6\nPart 1 answer: 6\n",
+			mainTest: mainTest{code: EXIT_FAILED, stderr: "input_answers.txt:2: part 1 is listed more than once"},
+		},
+		{
+			name:     "malformed line",
+			answers:  "Part 1 answer: 6\nPart two answer: a+b+c\n",
+			mainTest: mainTest{code: EXIT_FAILED, stderr: "input_answers.txt:2: 'Part two answer: a+b+c' does not match"},
+		},
+	}
+
+	for _, test := range tests {
+		files := map[string]string{"input.txt": "a\nb\nc\n"}
+		if len(test.answers) > 0 {
+			files["input_answers.txt"] = test.answers
+		}
+		dir := writeInputs(t, files)
+
+		test.mainTest.name = test.name
+		test.mainTest.args = append([]string{"-input", filepath.Join(dir, "input.txt"), "-verify"}, test.mainTest.args...)
+		runMainTests(t, dir, []mainTest{test.mainTest})
+	}
+
+	// standard input has nothing to verify against
+	runMainTests(t, "", []mainTest{{name: "stdin", args: []string{"-input", "-", "-verify"}, code: EXIT_FAILED, stderr: "standard input has no expected-answers file"}})
+}
+
+func TestVerifyJSON(t *testing.T) {
+	dir := writeInputs(t, map[string]string{
+		"input.txt":         "a\nb\nc\n",
+		"input_answers.txt": "Part 1 answer: 6\n",
+	})
+
+	results := runJSON(t, "-input", filepath.Join(dir, "input.txt"), "-verify")
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	if r := results[0]; r.Expected != "6" || r.Pass == nil || !*r.Pass {
+		t.Errorf("part 1: got expected answer '%s' and pass %v, want 6 and true", r.Expected, r.Pass)
+	}
+	if r := results[1]; len(r.Expected) > 0 || r.Pass != nil {
+		t.Errorf("part 2: got expected answer '%s' and pass %v with none listed", r.Expected, r.Pass)
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Package solver provides the command-line interface shared by every Go day of Advent of Code
+gosolver.go: Laura Galbraith
+*/
+package solver
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Part solves one part of a day's puzzle from the parsed input, returning the answer
+type Part[T any] func(puzzle T, params Params) (any, error)
+
+// Explained answers have more to say than fits on the answer line, e.g. why there is no answer
+// The explanation is written to stderr after the answer, so it never mixes with answers being read by other programs
+type Explained interface {
+	Explanation() string
+}
+
+// Day describes how to solve a day's puzzle: how to parse its input once, and how to solve each part from that
+type Day[T any] struct {
+	Number int
+
+	// Params are the default puzzle parameters; only names listed here may be overridden on the command line
+	Params Params
+	// InputParams are defaults for particular inputs (by file name, without directory), e.g. the example input's smaller limits
+	InputParams map[string]Params
+
+	// Parse reads the named input file ("-" for stdin) into whatever form the parts share
+	// NOTE: parts may be run one after the other on the same parsed input, so they should not modify it
+	Parse func(input_name string, params Params) (T, error)
+	Parts []Part[T]
+}
+
+// Output formats
+const (
+	FORMAT_TEXT  = "text"  // "Part 1 answer: 42"
+	FORMAT_PLAIN = "plain" // "42"
+	FORMAT_JSON  = "json"  // one Result object per line
+)
+
+const (
+	DEFAULT_INPUT = "input.txt"
+	ALL_PARTS     = "all"
+)
+
+// Exit codes
+const (
+	EXIT_OK     = 0
+	EXIT_FAILED = 1
+	EXIT_USAGE  = 2
+)
+
+// Options are the choices made on the command line
+type Options struct {
+	InputName string
+	Parts     []int
+	Params    Params
+	Format    string
+	Verify    bool // compare answers against the input's expected-answers file
+}
+
+// Run solves the day as directed by the program's command-line arguments, then exits the program
+func Run[T any](day Day[T]) {
+	os.Exit(day.Main(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Main solves the day as directed by args (not including the program name), returning the exit code
+func (day Day[T]) Main(args []string, stdout, stderr io.Writer) int {
+	opts, err := day.ParseArgs(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return EXIT_OK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "day %d: %v\n", day.Number, err)
+		return EXIT_USAGE
+	}
+
+	if err := day.Solve(opts, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "day %d: %v\n", day.Number, err)
+		return EXIT_FAILED
+	}
+
+	return EXIT_OK
+}
+
+// ParseArgs reads the command-line flags into Options, filling in defaults for anything not given
+func (day Day[T]) ParseArgs(args []string, stderr io.Writer) (Options, error) {
+	fs := flag.NewFlagSet(fmt.Sprintf("day%02d", day.Number), flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	input_name := fs.String("input", DEFAULT_INPUT, "input file to solve (\"-\" for stdin)")
+	parts := fs.String("part", ALL_PARTS, "comma-separated parts to solve, or \""+ALL_PARTS+"\"")
+	format := fs.String("format", FORMAT_TEXT, "answer output format: "+FORMAT_TEXT+", "+FORMAT_PLAIN+" or "+FORMAT_JSON)
+	verify := fs.Bool("verify", false, "check answers against the expected answers kept next to the input, e.g. input_answers.txt for input.txt")
+	var overrides paramFlag
+	fs.Var(&overrides, "param", "override a puzzle parameter as name=value (repeatable)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+		fs.PrintDefaults()
+
+		if len(day.Params) > 0 {
+			fmt.Fprintf(fs.Output(), "Puzzle parameters (defaults for %s):\n", DEFAULT_INPUT)
+			defaults := day.paramsFor(DEFAULT_INPUT)
+			for _, name := range defaults.names() {
+				fmt.Fprintf(fs.Output(), "  %s=%s\n", name, defaults[name])
+			}
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Options{}, err
+	}
+	if fs.NArg() > 0 {
+		return Options{}, fmt.Errorf("unexpected arguments: %q", fs.Args())
+	}
+
+	opts := Options{InputName: *input_name, Format: *format, Verify: *verify}
+
+	// validate choices
+	switch opts.Format {
+	case FORMAT_TEXT, FORMAT_PLAIN, FORMAT_JSON:
+	default:
+		return Options{}, fmt.Errorf("unknown output format '%s'", opts.Format)
+	}
+
+	var err error
+	opts.Parts, err = day.parsePartList(*parts)
+	if err != nil {
+		return Options{}, err
+	}
+
+	for name := range overrides.params {
+		if _, known := day.Params[name]; !known {
+			return Options{}, fmt.Errorf("unknown puzzle parameter '%s'", name)
+		}
+	}
+	opts.Params = day.paramsFor(opts.InputName).merge(overrides.params)
+
+	return opts, nil
+}
+
+// paramsFor returns the default parameters for the named input
+func (day Day[T]) paramsFor(input_name string) Params {
+	return day.Params.merge(day.InputParams[filepath.Base(input_name)])
+}
+
+// parsePartList turns "all" or a list like "1,2" into 1-indexed part numbers
+func (day Day[T]) parsePartList(list string) ([]int, error) {
+	parts := []int{}
+
+	if list == ALL_PARTS {
+		for part := 1; part <= len(day.Parts); part++ {
+			parts = append(parts, part)
+		}
+		return parts, nil
+	}
+
+	for _, part_str := range strings.Split(list, ",") {
+		part, err := strconv.Atoi(strings.TrimSpace(part_str))
+		if err != nil || part < 1 || part > len(day.Parts) {
+			return nil, fmt.Errorf("part must be between 1 and %d, not '%s'", len(day.Parts), part_str)
+		}
+
+		parts = append(parts, part)
+	}
+
+	return parts, nil
+}
+
+// Solve parses the input and solves each chosen part, writing answers to w, and any explanations of them to stderr
+func (day Day[T]) Solve(opts Options, w, stderr io.Writer) error {
+	input_name := opts.InputName
+
+	// load expected answers first, so a missing file is reported before spending time solving
+	var v *verification
+	if opts.Verify {
+		answers_name, err := AnswersFileName(input_name)
+		if err != nil {
+			return err
+		}
+
+		expected, err := ReadExpectedAnswers(answers_name)
+		if err != nil {
+			return err
+		}
+
+		v = &verification{expected: expected}
+	}
+
+	// only JSON output reports the checksum, so only then is the input read an extra time
+	var checksum string
+	if opts.Format == FORMAT_JSON {
+		var cleanup func()
+		var err error
+		checksum, input_name, cleanup, err = checksumInput(input_name)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+
+	puzzle, err := day.parse(input_name, opts.Params)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range opts.Parts {
+		start := time.Now()
+		answer, err := day.solvePart(part, puzzle, opts.Params)
+		elapsed := time.Since(start)
+		if err != nil {
+			return fmt.Errorf("part %d: %w", part, err)
+		}
+
+		result := Result{
+			Day:         day.Number,
+			Part:        part,
+			Answer:      fmt.Sprint(answer),
+			Input:       opts.InputName,
+			InputSHA256: checksum,
+			ElapsedNS:   elapsed.Nanoseconds(),
+		}
+
+		// describe how the answer compares to the expected one, if verifying
+		verdict := ""
+		if v != nil {
+			pass, expected, found := v.check(part, result.Answer)
+			if found {
+				result.Expected = expected
+				result.Pass = &pass
+			}
+
+			switch {
+			case !found:
+				verdict = " (no expected answer)"
+			case pass:
+				verdict = " (PASS)"
+			default:
+				verdict = fmt.Sprintf(" (FAIL: expected %s)", expected)
+			}
+		}
+
+		switch opts.Format {
+		case FORMAT_PLAIN:
+			fmt.Fprintf(w, "%s%s\n", result.Answer, verdict)
+		case FORMAT_JSON:
+			if err := json.NewEncoder(w).Encode(result); err != nil {
+				return err
+			}
+		default:
+			fmt.Fprintf(w, "Part %d answer: %s%s\n", part, result.Answer, verdict)
+		}
+
+		if e, explained := answer.(Explained); explained {
+			fmt.Fprintf(stderr, "Part %d: %s\n", part, strings.TrimSuffix(e.Explanation(), "\n"))
+		}
+	}
+
+	if v != nil {
+		return v.err()
+	}
+
+	return nil
+}
+
+// recoverAsError turns a panic into an error stored in err, so the program can still exit cleanly; it must be deferred
+func recoverAsError(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("panic: %v", r)
+	}
+}
+
+func (day Day[T]) parse(input_name string, params Params) (puzzle T, err error) {
+	defer recoverAsError(&err)
+
+	return day.Parse(input_name, params)
+}
+
+func (day Day[T]) solvePart(part int, puzzle T, params Params) (answer any, err error) {
+	defer recoverAsError(&err)
+
+	return day.Parts[part-1](puzzle, params)
+}
@@ -0,0 +1,156 @@
+package solver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+)
+
+// TEST_DAY counts and joins the lines of its input; its "fail" parameter makes part 2 fail with an error or a panic
+var TEST_DAY = Day[[]string]{
+	Number: 99,
+	Params: Params{
+		"scale": "2",
+		"fail":  "",
+	},
+	InputParams: map[string]Params{
+		"example.txt": {"scale": "3"},
+	},
+	Parse: func(input_name string, _ Params) ([]string, error) {
+		return fileutil.GetLinesFromFile(input_name)
+	},
+	Parts: []Part[[]string]{
+		func(lines []string, params Params) (any, error) {
+			scale, err := params.Int("scale")
+			return len(lines) * scale, err
+		},
+		func(lines []string, params Params) (any, error) {
+			switch params["fail"] {
+			case "error":
+				return nil, errors.New("part 2 failed")
+			case "panic":
+				panic("part 2 panicked")
+			}
+
+			return strings.Join(lines, "+"), nil
+		},
+	},
+}
+
+// writeInputs writes each named file to a new directory, returning the directory
+func writeInputs(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// mainTest is a run of TEST_DAY's Main; "$DIR" in its arguments is replaced by the directory of inputs
+type mainTest struct {
+	name   string
+	args   []string
+	code   int
+	stdout string
+	stderr string // which stderr must contain
+}
+
+func runMainTests(t *testing.T, dir string, tests []mainTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		args := make([]string, len(test.args))
+		for i, arg := range test.args {
+			args[i] = strings.ReplaceAll(arg, "$DIR", dir)
+		}
+
+		var stdout, stderr strings.Builder
+		code := TEST_DAY.Main(args, &stdout, &stderr)
+
+		if code != test.code {
+			t.Errorf("%s: got exit code %d, want %d (stderr: %s)", test.name, code, test.code, stderr.String())
+		}
+		if stdout.String() != test.stdout {
+			t.Errorf("%s: got stdout\n%s\nwant\n%s", test.name, stdout.String(), test.stdout)
+		}
+		if !strings.Contains(stderr.String(), test.stderr) {
+			t.Errorf("%s: got stderr\n%s\nwant it to contain\n%s", test.name, stderr.String(), test.stderr)
+		}
+	}
+}
+
+func TestDayMain(t *testing.T) {
+	dir := writeInputs(t, map[string]string{
+		"input.txt":   "a\nb\nc\n",
+		"example.txt": "d\ne\n",
+	})
+	input := filepath.Join(dir, "input.txt")
+	example := filepath.Join(dir, "example.txt")
+
+	runMainTests(t, dir, []mainTest{
+		{name: "every part", args: []string{"-input", input}, stdout: "Part 1 answer: 6\nPart 2 answer: a+b+c\n"},
+		{name: "one part", args: []string{"-input", input, "-part", "2"}, stdout: "Part 2 answer: a+b+c\n"},
+		{name: "parts in the order listed", args: []string{"-input", input, "-part", "2, 1"}, stdout: "Part 2 answer: a+b+c\nPart 1 answer: 6\n"},
+		{name: "plain format", args: []string{"-input", input, "-format", "plain"}, stdout: "6\na+b+c\n"},
+		{name: "parameter", args: []string{"-input", input, "-param", "scale=5", "-part", "1"}, stdout: "Part 1 answer: 15\n"},
+		{name: "last parameter wins", args: []string{"-input", input, "-param", "scale=5", "-param", "scale=7", "-part", "1"}, stdout: "Part 1 answer: 21\n"},
+		{name: "input's parameters", args: []string{"-input", example, "-part", "1"}, stdout: "Part 1 answer: 6\n"},
+		{name: "parameter over input's", args: []string{"-input", example, "-param", "scale=5", "-part", "1"}, stdout: "Part 1 answer: 10\n"},
+
+		{name: "help", args: []string{"-help"}, stderr: "scale=2"},
+		{name: "part too high", args: []string{"-input", input, "-part", "3"}, code: EXIT_USAGE, stderr: "part must be between 1 and 2, not '3'"},
+		{name: "part 0", args: []string{"-input", input, "-part", "0"}, code: EXIT_USAGE, stderr: "part must be between 1 and 2"},
+		{name: "part not a number", args: []string{"-input", input, "-part", "1,x"}, code: EXIT_USAGE, stderr: "not 'x'"},
+		{name: "unknown parameter", args: []string{"-input", input, "-param", "rounds=3"}, code: EXIT_USAGE, stderr: "unknown puzzle parameter 'rounds'"},
+		{name: "parameter without value", args: []string{"-input", input, "-param", "scale"}, code: EXIT_USAGE, stderr: "expected name=value"},
+		{name: "unknown format", args: []string{"-input", input, "-format", "xml"}, code: EXIT_USAGE, stderr: "unknown output format 'xml'"},
+		{name: "extra arguments", args: []string{"-input", input, "1"}, code: EXIT_USAGE, stderr: "unexpected arguments"},
+
+		{name: "missing input", args: []string{"-input", filepath.Join(dir, "missing.txt")}, code: EXIT_FAILED, stderr: "day 99: open " + filepath.Join(dir, "missing.txt")},
+		{name: "bad parameter value", args: []string{"-input", input, "-param", "scale=x"}, code: EXIT_FAILED, stderr: "part 1: parameter 'scale'"},
+		{name: "part error", args: []string{"-input", input, "-param", "fail=error"}, code: EXIT_FAILED, stdout: "Part 1 answer: 6\n", stderr: "day 99: part 2: part 2 failed"},
+		{name: "part panic", args: []string{"-input", input, "-param", "fail=panic"}, code: EXIT_FAILED, stdout: "Part 1 answer: 6\n", stderr: "day 99: part 2: panic: part 2 panicked"},
+	})
+}
+
+func TestParams(t *testing.T) {
+	p := Params{"n": "-3", "u": "4", "b": "true", "s": "text"}
+
+	if n, err := p.Int("n"); err != nil || n != -3 {
+		t.Errorf("Int: got %d (error %v)", n, err)
+	}
+	if n, err := p.Int64("n"); err != nil || n != -3 {
+		t.Errorf("Int64: got %d (error %v)", n, err)
+	}
+	if u, err := p.Uint("u"); err != nil || u != 4 {
+		t.Errorf("Uint: got %d (error %v)", u, err)
+	}
+	if b, err := p.Bool("b"); err != nil || !b {
+		t.Errorf("Bool: got %v (error %v)", b, err)
+	}
+
+	if _, err := p.Uint("n"); err == nil {
+		t.Error("Uint: got no error for a negative number")
+	}
+	if _, err := p.Bool("s"); err == nil {
+		t.Error("Bool: got no error for text")
+	}
+	if _, err := p.String("missing"); err == nil {
+		t.Error("String: got no error for a missing parameter")
+	}
+
+	merged := p.merge(Params{"n": strconv.Itoa(5)})
+	if merged["n"] != "5" || p["n"] != "-3" {
+		t.Errorf("merge: got %s in the copy and %s in the original, want 5 and -3", merged["n"], p["n"])
+	}
+}
@@ -0,0 +1,136 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Params are the named values a puzzle is solved with (e.g. how many rounds to simulate), kept as text as they are given on the command line
+type Params map[string]string
+
+func (p Params) lookup(name string) (string, error) {
+	val, found := p[name]
+	if !found {
+		return "", fmt.Errorf("parameter '%s' is not defined", name)
+	}
+
+	return val, nil
+}
+
+func (p Params) String(name string) (string, error) {
+	return p.lookup(name)
+}
+
+func (p Params) Int(name string) (int, error) {
+	val, err := p.lookup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	num, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("parameter '%s': %w", name, err)
+	}
+
+	return num, nil
+}
+
+func (p Params) Int64(name string) (int64, error) {
+	val, err := p.lookup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	num, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parameter '%s': %w", name, err)
+	}
+
+	return num, nil
+}
+
+func (p Params) Uint(name string) (uint, error) {
+	val, err := p.lookup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	num, err := strconv.ParseUint(val, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("parameter '%s': %w", name, err)
+	}
+
+	return uint(num), nil
+}
+
+func (p Params) Bool(name string) (bool, error) {
+	val, err := p.lookup(name)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("parameter '%s': %w", name, err)
+	}
+
+	return b, nil
+}
+
+// merge returns a copy of p with every value of overrides applied on top
+func (p Params) merge(overrides Params) Params {
+	merged := make(Params, len(p)+len(overrides))
+	for name, val := range p {
+		merged[name] = val
+	}
+	for name, val := range overrides {
+		merged[name] = val
+	}
+
+	return merged
+}
+
+// names returns the parameter names in sorted order, so they can be listed deterministically
+func (p Params) names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// paramFlag collects repeated "-param name=value" command-line flags
+type paramFlag struct {
+	params Params
+}
+
+func (f *paramFlag) String() string {
+	if f == nil {
+		return ""
+	}
+
+	pairs := []string{}
+	for _, name := range f.params.names() {
+		pairs = append(pairs, name+"="+f.params[name])
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (f *paramFlag) Set(pair string) error {
+	name, val, found := strings.Cut(pair, "=")
+	if !found || len(name) == 0 {
+		return fmt.Errorf("expected name=value, got '%s'", pair)
+	}
+
+	if f.params == nil {
+		f.params = make(Params)
+	}
+	f.params[name] = val
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package solver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+)
+
+// Result is the JSON record written for each part solved when the output format is FORMAT_JSON
+type Result struct {
+	Day  int `json:"day"`
+	Part int `json:"part"`
+	// NOTE: answers are written as strings, since many are too large to be read back exactly as JSON numbers
+	Answer      string `json:"answer"`
+	Input       string `json:"input"`
+	InputSHA256 string `json:"input_sha256"` // of the input exactly as stored, e.g. before decompression
+	ElapsedNS   int64  `json:"elapsed_ns"`   // time spent solving the part, not including parsing the input
+
+	// only set when verifying, and the input's expected-answers file lists this part
+	Expected string `json:"expected,omitempty"`
+	Pass     *bool  `json:"pass,omitempty"`
+}
+
+// checksumInput returns the SHA-256 of the named input, plus the name the input can be read from afterwards
+// Standard input can only be read once, so it is saved to a temporary file as it is checksummed; cleanup removes it
+func checksumInput(input_name string) (checksum string, readable_name string, cleanup func(), err error) {
+	cleanup = func() {}
+	hash := sha256.New()
+
+	r, err := fileutil.OpenInput(input_name)
+	if err != nil {
+		return "", "", cleanup, err
+	}
+	defer r.Close()
+
+	if input_name != fileutil.STDIN_NAME {
+		if _, err := io.Copy(hash, r); err != nil {
+			return "", "", cleanup, err
+		}
+
+		return hex.EncodeToString(hash.Sum(nil)), input_name, cleanup, nil
+	}
+
+	spool, err := os.CreateTemp("", "stdin-*")
+	if err != nil {
+		return "", "", cleanup, err
+	}
+	defer spool.Close()
+	cleanup = func() { os.Remove(spool.Name()) }
+
+	if _, err := io.Copy(io.MultiWriter(hash, spool), r); err != nil {
+		cleanup()
+		return "", "", func() {}, err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), spool.Name(), cleanup, nil
+}
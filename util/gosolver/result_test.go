@@ -0,0 +1,80 @@
+package solver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fileutil "github.com/lauragalbraith/AdventOfCode2022/util/gofileutil"
+)
+
+// runJSON runs TEST_DAY with JSON output, returning its results
+func runJSON(t *testing.T, args ...string) []Result {
+	t.Helper()
+
+	var stdout, stderr strings.Builder
+	if code := TEST_DAY.Main(append(args, "-format", FORMAT_JSON), &stdout, &stderr); code != EXIT_OK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+
+	results := []Result{}
+	d := json.NewDecoder(strings.NewReader(stdout.String()))
+	for d.More() {
+		var r Result
+		if err := d.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+
+	return results
+}
+
+func TestJSONFormat(t *testing.T) {
+	const INPUT = "a\nb\nc\n"
+	dir := writeInputs(t, map[string]string{"input.txt": INPUT})
+	input := filepath.Join(dir, "input.txt")
+
+	sum := sha256.Sum256([]byte(INPUT))
+	checksum := hex.EncodeToString(sum[:])
+
+	// standard input is checksummed too, and still read afterwards
+	stdin, err := os.Open(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+
+	real_stdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = real_stdin }()
+
+	for _, input_name := range []string{input, fileutil.STDIN_NAME} {
+		results := runJSON(t, "-input", input_name)
+		if len(results) != 2 {
+			t.Fatalf("%s: got %d results, want 2", input_name, len(results))
+		}
+
+		for i, want_answer := range []string{"6", "a+b+c"} {
+			r := results[i]
+			if r.Day != 99 || r.Part != i+1 || r.Answer != want_answer || r.Input != input_name {
+				t.Errorf("%s: got result %+v, want day 99 part %d answer %s", input_name, r, i+1, want_answer)
+			}
+			if r.InputSHA256 != checksum {
+				t.Errorf("%s, part %d: got checksum %s, want %s", input_name, i+1, r.InputSHA256, checksum)
+			}
+			if r.ElapsedNS < 0 {
+				t.Errorf("%s, part %d: got elapsed time %d", input_name, i+1, r.ElapsedNS)
+			}
+
+			// only verifying fills these in
+			if len(r.Expected) > 0 || r.Pass != nil {
+				t.Errorf("%s, part %d: got expected answer %s and pass %v without verifying", input_name, i+1, r.Expected, r.Pass)
+			}
+		}
+	}
+}